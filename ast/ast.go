@@ -3,6 +3,8 @@ package ast
 import (
 	"bytes"
 	"go-interpreter/token"
+	"math/big"
+	"strings"
 )
 
 // LetStatement : represents a let statement, comprised of
@@ -24,9 +26,34 @@ type ExpressionStatement struct {
 	Expression Expression  //
 }
 
+// BlockStatement : a series of statements enclosed in braces,
+// e.g. the consequence and alternative of an if expression
+type BlockStatement struct {
+	Token      token.Token // the { token
+	Statements []Statement
+	Rbrace     token.Pos // position of the closing }, if there was one
+}
+
 type IntegerLiteral struct {
 	Token token.Token // token.INT
 	Value int64
+	Big   *big.Int // the value instead, if it is too large for an int64
+}
+
+// FloatLiteral : a number with a fraction or exponent, e.g. 1.5 or 1e9
+type FloatLiteral struct {
+	Token token.Token // token.FLOAT
+	Value float64
+}
+
+type StringLiteral struct {
+	Token token.Token // token.STRING
+	Value string
+}
+
+type Boolean struct {
+	Token token.Token // token.TRUE or token.FALSE
+	Value bool
 }
 
 type PrefixExpression struct {
@@ -42,17 +69,93 @@ type InfixExpression struct {
 	Right    Expression
 }
 
+// FunctionLiteral : fn(<parameters>) <body>
+type FunctionLiteral struct {
+	Token      token.Token // token.FUNCTION token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+// CallExpression : <function>(<arguments>), where function is
+// either an identifier or a function literal
+type CallExpression struct {
+	Token     token.Token // the ( token
+	Function  Expression
+	Arguments []Expression
+	Rparen    token.Pos // position of the closing )
+}
+
+// ArrayLiteral : [<expression>, <expression>, ...]
+type ArrayLiteral struct {
+	Token    token.Token // the [ token
+	Elements []Expression
+	Rbracket token.Pos // position of the closing ]
+}
+
+// IndexExpression : <expression>[<expression>]
+type IndexExpression struct {
+	Token    token.Token // the [ token
+	Left     Expression
+	Index    Expression
+	Rbracket token.Pos // position of the closing ]
+}
+
+// HashLiteral : {<expression>: <expression>, ...}, the pairs
+// are kept in the order they were written in
+type HashLiteral struct {
+	Token  token.Token // the { token
+	Pairs  []HashPair
+	Rbrace token.Pos // position of the closing }
+}
+
+// HashPair : a single key: value pair of a hash literal
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+// AssignExpression : <target> = <value>, or a compound assignment
+// such as <target> += <value>, where target is an identifier or an
+// index expression, e.g. x = 5 or arr[i] -= 1
+type AssignExpression struct {
+	Token    token.Token // the = or compound assignment token
+	Target   Expression
+	Operator string
+	Value    Expression
+}
+
+// IfExpression : if (<condition>) <consequence> else <alternative>,
+// an else if chain is represented by an alternative block holding
+// a single nested if expression
+type IfExpression struct {
+	Token       token.Token // token.IF token
+	Condition   Expression
+	Consequence *BlockStatement
+	Alternative *BlockStatement // nil when there is no else branch
+}
+
 // dummy methods which will result in these structs
 // implementing the Statement interface
 func (ls *LetStatement) statementNode()        {}
 func (rs *ReturnStatement) statementNode()     {}
 func (es *ExpressionStatement) statementNode() {}
+func (bs *BlockStatement) statementNode()      {}
 
 // dummy methods which will result in these structs
 // implementing the statement interface
 func (il *IntegerLiteral) expressionNode()   {}
+func (fl *FloatLiteral) expressionNode()     {}
+func (b *Boolean) expressionNode()           {}
+func (sl *StringLiteral) expressionNode()    {}
 func (pe *PrefixExpression) expressionNode() {}
 func (ie *InfixExpression) expressionNode()  {}
+func (ie *IfExpression) expressionNode()     {}
+func (fl *FunctionLiteral) expressionNode()  {}
+func (ce *CallExpression) expressionNode()   {}
+func (al *ArrayLiteral) expressionNode()     {}
+func (ie *IndexExpression) expressionNode()  {}
+func (hl *HashLiteral) expressionNode()      {}
+func (ae *AssignExpression) expressionNode() {}
 
 // TokenLiteral functions to satisfy Node interface
 func (ls *LetStatement) TokenLiteral() string {
@@ -66,10 +169,26 @@ func (es *ExpressionStatement) TokenLiteral() string {
 	return es.Token.Literal
 }
 
+func (bs *BlockStatement) TokenLiteral() string {
+	return bs.Token.Literal
+}
+
 func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
 
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+func (sl *StringLiteral) TokenLiteral() string {
+	return sl.Token.Literal
+}
+
+func (b *Boolean) TokenLiteral() string {
+	return b.Token.Literal
+}
+
 func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
@@ -78,6 +197,122 @@ func (ie *InfixExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *IfExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (fl *FunctionLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+func (ce *CallExpression) TokenLiteral() string {
+	return ce.Token.Literal
+}
+
+func (al *ArrayLiteral) TokenLiteral() string {
+	return al.Token.Literal
+}
+
+func (ie *IndexExpression) TokenLiteral() string {
+	return ie.Token.Literal
+}
+
+func (hl *HashLiteral) TokenLiteral() string {
+	return hl.Token.Literal
+}
+
+func (ae *AssignExpression) TokenLiteral() string {
+	return ae.Token.Literal
+}
+
+// End functions to satisfy Node interface, every node ends just after
+// the last character of its source, e.g. the ] of an index expression
+func (ls *LetStatement) End() token.Pos        { return end(ls.Value, ls.Token) }
+func (rs *ReturnStatement) End() token.Pos     { return end(rs.ReturnValue, rs.Token) }
+func (es *ExpressionStatement) End() token.Pos { return end(es.Expression, es.Token) }
+func (bs *BlockStatement) End() token.Pos      { return after(bs.Rbrace) }
+func (il *IntegerLiteral) End() token.Pos      { return il.Token.End }
+func (fl *FloatLiteral) End() token.Pos        { return fl.Token.End }
+func (sl *StringLiteral) End() token.Pos       { return sl.Token.End }
+func (b *Boolean) End() token.Pos              { return b.Token.End }
+func (i *Identifier) End() token.Pos           { return i.Token.End }
+func (pe *PrefixExpression) End() token.Pos    { return end(pe.Right, pe.Token) }
+func (ie *InfixExpression) End() token.Pos     { return end(ie.Right, ie.Token) }
+func (ae *AssignExpression) End() token.Pos    { return end(ae.Value, ae.Token) }
+func (fl *FunctionLiteral) End() token.Pos     { return fl.Body.End() }
+func (ce *CallExpression) End() token.Pos      { return after(ce.Rparen) }
+func (al *ArrayLiteral) End() token.Pos        { return after(al.Rbracket) }
+func (ie *IndexExpression) End() token.Pos     { return after(ie.Rbracket) }
+func (hl *HashLiteral) End() token.Pos         { return after(hl.Rbrace) }
+
+func (ie *IfExpression) End() token.Pos {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	return ie.Consequence.End()
+}
+
+// end : the end of node, or of tok if there is no node, e.g.
+// the return keyword of a return statement with no value
+func end(node Node, tok token.Token) token.Pos {
+	if node == nil {
+		return tok.End
+	}
+	return node.End()
+}
+
+// after : the position just after the one character token at pos
+func after(pos token.Pos) token.Pos {
+	pos.Column++
+	pos.Offset++
+	return pos
+}
+
+// Pos functions to satisfy Node interface, every node is positioned
+// at the first token of its source, e.g. the left side of an infix
+// expression rather than its operator
+func (ls *LetStatement) Pos() token.Pos        { return ls.Token.Pos }
+func (rs *ReturnStatement) Pos() token.Pos     { return rs.Token.Pos }
+func (es *ExpressionStatement) Pos() token.Pos { return es.Token.Pos }
+func (bs *BlockStatement) Pos() token.Pos      { return bs.Token.Pos }
+func (il *IntegerLiteral) Pos() token.Pos      { return il.Token.Pos }
+func (fl *FloatLiteral) Pos() token.Pos        { return fl.Token.Pos }
+func (sl *StringLiteral) Pos() token.Pos       { return sl.Token.Pos }
+func (b *Boolean) Pos() token.Pos              { return b.Token.Pos }
+func (pe *PrefixExpression) Pos() token.Pos    { return pe.Token.Pos }
+func (ie *IfExpression) Pos() token.Pos        { return ie.Token.Pos }
+func (fl *FunctionLiteral) Pos() token.Pos     { return fl.Token.Pos }
+func (al *ArrayLiteral) Pos() token.Pos        { return al.Token.Pos }
+func (hl *HashLiteral) Pos() token.Pos         { return hl.Token.Pos }
+
+func (ie *InfixExpression) Pos() token.Pos {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+	return ie.Token.Pos
+}
+
+func (ce *CallExpression) Pos() token.Pos {
+	if ce.Function != nil {
+		return ce.Function.Pos()
+	}
+	return ce.Token.Pos
+}
+
+func (ie *IndexExpression) Pos() token.Pos {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+	return ie.Token.Pos
+}
+
+func (ae *AssignExpression) Pos() token.Pos {
+	if ae.Target != nil {
+		return ae.Target.Pos()
+	}
+	return ae.Token.Pos
+}
+
 // String functions to satisfy node interface
 
 func (ls *LetStatement) String() string {
@@ -118,10 +353,32 @@ func (es *ExpressionStatement) String() string {
 	return ""
 }
 
+func (bs *BlockStatement) String() string {
+	var out bytes.Buffer
+
+	for _, s := range bs.Statements {
+		out.WriteString(s.String())
+	}
+
+	return out.String()
+}
+
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
+func (sl *StringLiteral) String() string {
+	return sl.Token.Literal
+}
+
+func (b *Boolean) String() string {
+	return b.Token.Literal
+}
+
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -144,6 +401,107 @@ func (ie *InfixExpression) String() string {
 	return out.String()
 }
 
+func (ie *IfExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("if")
+	out.WriteString(ie.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ie.Consequence.String())
+
+	if ie.Alternative != nil {
+		out.WriteString("else ")
+		out.WriteString(ie.Alternative.String())
+	}
+
+	return out.String()
+}
+
+func (fl *FunctionLiteral) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range fl.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(fl.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") ")
+	out.WriteString(fl.Body.String())
+
+	return out.String()
+}
+
+func (ce *CallExpression) String() string {
+	var out bytes.Buffer
+
+	args := []string{}
+	for _, a := range ce.Arguments {
+		args = append(args, a.String())
+	}
+
+	out.WriteString(ce.Function.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (al *ArrayLiteral) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+	for _, el := range al.Elements {
+		elements = append(elements, el.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+func (hl *HashLiteral) String() string {
+	var out bytes.Buffer
+
+	pairs := []string{}
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+":"+pair.Value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.Operator + " ")
+	out.WriteString(ae.Value.String())
+
+	return out.String()
+}
+
 func (i *Identifier) String() string { return i.Value }
 
 func (p *Program) String() string {
@@ -171,12 +529,18 @@ func (i *Identifier) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+// Pos : get position of an identifier
+func (i *Identifier) Pos() token.Pos { return i.Token.Pos }
+
 // Node : implementers of the Node interface must
 // include a TokenLiteral method that
-// returns the literal value of a token
+// returns the literal value of a token, and a Pos
+// method that returns where in the source it starts
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Pos
+	End() token.Pos
 }
 
 // Statements do not return a value
@@ -208,3 +572,19 @@ func (p *Program) TokenLiteral() string {
 	}
 	return ""
 }
+
+// End : end of the last statement of the program
+func (p *Program) End() token.Pos {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Pos{}
+}
+
+// Pos : position of the first statement of the program
+func (p *Program) Pos() token.Pos {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Pos{}
+}
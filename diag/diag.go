@@ -0,0 +1,213 @@
+package diag
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"go-interpreter/object"
+	"go-interpreter/parser"
+	"go-interpreter/token"
+)
+
+// Diagnostic : a problem found in a program, positioned at the
+// Length bytes of source starting at Pos, with an optional hint
+// on how it may be fixed
+type Diagnostic struct {
+	Pos      token.Pos
+	Length   int
+	Severity string
+	Message  string
+	Hint     string
+}
+
+// FromParseError : create a diagnostic for an error found while parsing
+// input, the underline covers the token the error was found at
+func FromParseError(input string, err *parser.Error) Diagnostic {
+	return Diagnostic{
+		Pos:      err.Pos,
+		Length:   tokenLength(input, err.Actual),
+		Severity: err.Severity.String(),
+		Message:  err.Msg,
+		Hint:     parseErrorHint(err),
+	}
+}
+
+// FromRuntimeError : create a diagnostic for an error produced
+// while evaluating a program, the underline covers the source of
+// the expression that failed
+func FromRuntimeError(err *object.Error) Diagnostic {
+	length := 1
+	if err.End.Offset > err.Pos.Offset {
+		length = err.End.Offset - err.Pos.Offset
+	}
+
+	return Diagnostic{
+		Pos:      err.Pos,
+		Length:   length,
+		Severity: "error",
+		Message:  err.Message,
+		Hint:     runtimeErrorHint(err),
+	}
+}
+
+// PrintParseErrors : render each of errs against input, in order
+func PrintParseErrors(w io.Writer, input string, errs parser.ErrorList) {
+	for _, err := range errs {
+		FromParseError(input, err).Render(w, input)
+	}
+}
+
+// PrintRuntimeError : render err against input
+func PrintRuntimeError(w io.Writer, input string, err *object.Error) {
+	FromRuntimeError(err).Render(w, input)
+}
+
+// Render : write the diagnostic to w, showing the line of input it
+// is on with the offending source underlined, e.g.
+//
+//	3:9: error: no prefix parse function for ; found
+//	   3 | let z = ;
+//	     |         ^
+//	     = hint: an expression is missing before the ;
+func (d Diagnostic) Render(w io.Writer, input string) {
+	fmt.Fprintf(w, "%s: %s: %s\n", d.Pos, d.Severity, d.Message)
+
+	if d.Pos.IsValid() {
+		line := sourceLine(input, d.Pos.Line)
+		gutter := fmt.Sprintf("%4d", d.Pos.Line)
+		padding := strings.Repeat(" ", len(gutter))
+
+		fmt.Fprintf(w, "%s | %s\n", gutter, line)
+		fmt.Fprintf(w, "%s | %s\n", padding, underline(line, d.Pos.Column, d.Length))
+
+		if d.Hint != "" {
+			fmt.Fprintf(w, "%s = hint: %s\n", padding, d.Hint)
+		}
+	} else if d.Hint != "" {
+		fmt.Fprintf(w, "hint: %s\n", d.Hint)
+	}
+}
+
+// sourceLine : the text of the given line of input, lines start at 1
+func sourceLine(input string, line int) string {
+	lines := strings.Split(input, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimRight(lines[line-1], "\r")
+}
+
+// underline : a ^~~~ marker under length bytes of line starting at
+// column, which counts runes like the lexer. Tabs before the column
+// are kept so the marker lines up
+func underline(line string, column, length int) string {
+	var out strings.Builder
+
+	// start is the byte offset of the column in line
+	start := 0
+	for i := 0; i < column-1; i++ {
+		r, width := utf8.DecodeRuneInString(line[start:])
+		if r == '\t' {
+			out.WriteByte('\t')
+		} else {
+			out.WriteByte(' ')
+		}
+		if width > 0 {
+			start += width
+		}
+	}
+
+	// don't underline past the end of the line, the marker may
+	// still be one past the end, e.g. for an unexpected EOF
+	end := start + length
+	if end > len(line) {
+		end = len(line)
+	}
+	length = utf8.RuneCountInString(line[start:end])
+	if length < 1 {
+		length = 1
+	}
+
+	out.WriteByte('^')
+	out.WriteString(strings.Repeat("~", length-1))
+
+	return out.String()
+}
+
+// tokenLength : number of bytes of input that tok was lexed from. The
+// literal of a string token has had its quotes and escapes removed,
+// so its length is found by scanning the source instead
+func tokenLength(input string, tok token.Token) int {
+	switch tok.Type {
+	case token.EOF:
+		return 1
+	case token.STRING:
+		start := tok.Pos.Offset
+		for i := start + 1; i < len(input); i++ {
+			switch input[i] {
+			case '\\':
+				i++
+			case '"':
+				return i - start + 1
+			}
+		}
+		return len(input) - start
+	}
+
+	if len(tok.Literal) == 0 {
+		return 1
+	}
+	return len(tok.Literal)
+}
+
+func parseErrorHint(err *parser.Error) string {
+	actual := err.Actual
+
+	switch {
+	case actual.Type == token.ASSIGN && expects(err, token.RPAREN):
+		return "did you mean `==`?"
+	case actual.Type == token.ILLEGAL && !utf8.ValidString(actual.Literal):
+		return "the source must be encoded as UTF-8"
+	case actual.Type == token.ILLEGAL && strings.HasPrefix(actual.Literal, `"`):
+		return "strings must end with a closing \", and can only use the escapes \\n \\t \\\" \\\\ and \\u{...}"
+	case actual.Type == token.ILLEGAL && strings.HasPrefix(actual.Literal, "/*"):
+		return "block comments must end with */, and each /* nested inside needs its own */"
+	case actual.Type == token.ILLEGAL:
+		return fmt.Sprintf("%q is not valid in any expression", actual.Literal)
+	case expects(err, token.IDENT) && token.LookupIdent(actual.Literal) != token.IDENT:
+		return fmt.Sprintf("`%s` is a keyword, and cannot be used as a name", actual.Literal)
+	case actual.Type == token.EOF:
+		return "the input ended before this statement was complete"
+	case len(err.Expected) == 0 && (actual.Type == token.SEMICOLON || actual.Type == token.RPAREN ||
+		actual.Type == token.RBRACE || actual.Type == token.RBRACKET ||
+		actual.Type == token.LET || actual.Type == token.RETURN):
+		return fmt.Sprintf("an expression is missing before the %s", actual.Literal)
+	}
+
+	return ""
+}
+
+func runtimeErrorHint(err *object.Error) string {
+	switch {
+	case strings.HasPrefix(err.Message, "identifier not found: "):
+		return "names must be bound with `let` before they are used"
+	case strings.HasPrefix(err.Message, "assignment to undeclared name: "):
+		return "names must be bound with `let` before they can be assigned to"
+	case strings.HasPrefix(err.Message, "type mismatch: "):
+		return "both sides of the operator must be of the same type"
+	}
+
+	return ""
+}
+
+// expects : whether t is one of the token types the parser expected
+func expects(err *parser.Error, t token.TokenType) bool {
+	for _, e := range err.Expected {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package diag
+
+import (
+	"bytes"
+	"go-interpreter/evaluator"
+	"go-interpreter/lexer"
+	"go-interpreter/object"
+	"go-interpreter/parser"
+	"testing"
+)
+
+func renderParseErrors(input string) string {
+	p := parser.New(lexer.New(input))
+	p.ParseProgram()
+
+	var out bytes.Buffer
+	PrintParseErrors(&out, input, p.Errors())
+	return out.String()
+}
+
+func TestParseErrorRendering(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let x = 1;\nif (x = 1) { x }",
+			"2:7: error: expected next token to be ), got = instead\n" +
+				"   2 | if (x = 1) { x }\n" +
+				"     |       ^\n" +
+				"     = hint: did you mean `==`?\n",
+		},
+		{
+			"let fn = 1;",
+			"1:5: error: expected next token to be IDENT, got FUNCTION instead\n" +
+				"   1 | let fn = 1;\n" +
+				"     |     ^~\n" +
+				"     = hint: `fn` is a keyword, and cannot be used as a name\n",
+		},
+		{
+			"\tlet z = ;",
+			"1:10: error: no prefix parse function for ; found\n" +
+				"   1 | \tlet z = ;\n" +
+				"     | \t        ^\n" +
+				"     = hint: an expression is missing before the ;\n",
+		},
+		{
+			"let s = \"abc",
+			"1:9: error: no prefix parse function for ILLEGAL found\n" +
+				"   1 | let s = \"abc\n" +
+				"     |         ^~~~\n" +
+				"     = hint: strings must end with a closing \", and can only use the escapes \\n \\t \\\" \\\\ and \\u{...}\n",
+		},
+		{
+			"let c = /* a /* b */ c",
+			"1:9: error: no prefix parse function for ILLEGAL found\n" +
+				"   1 | let c = /* a /* b */ c\n" +
+				"     |         ^~~~~~~~~~~~~~\n" +
+				"     = hint: block comments must end with */, and each /* nested inside needs its own */\n",
+		},
+		{
+			"let café = \"☕\" + ;",
+			"1:18: error: no prefix parse function for ; found\n" +
+				"   1 | let café = \"☕\" + ;\n" +
+				"     |                  ^\n" +
+				"     = hint: an expression is missing before the ;\n",
+		},
+		{
+			"let s = \"日本\" == 日 本 \xff;",
+			"1:21: error: invalid UTF-8 encoding \"\\xff\"\n" +
+				"   1 | let s = \"日本\" == 日 本 \xff;\n" +
+				"     |                     ^\n" +
+				"     = hint: the source must be encoded as UTF-8\n",
+		},
+		{
+			"let x = 1 +\nlet y = 2;",
+			"1:12: error: no prefix parse function for LET found\n" +
+				"   1 | let x = 1 +\n" +
+				"     |            ^\n" +
+				"     = hint: an expression is missing before the let\n",
+		},
+		{
+			"let a = (1 + 2",
+			"1:15: error: expected next token to be ), got EOF instead\n" +
+				"   1 | let a = (1 + 2\n" +
+				"     |               ^\n" +
+				"     = hint: the input ended before this statement was complete\n",
+		},
+		{
+			"let y = {\"a\\tb\" 5};",
+			"1:17: error: expected next token to be :, got INT instead\n" +
+				"   1 | let y = {\"a\\tb\" 5};\n" +
+				"     |                 ^\n",
+		},
+		{
+			"let y = (\"a\\tb\" \"c\");",
+			"1:17: error: expected next token to be ), got STRING instead\n" +
+				"   1 | let y = (\"a\\tb\" \"c\");\n" +
+				"     |                 ^~~\n",
+		},
+	}
+
+	for _, tt := range tests {
+		actual := renderParseErrors(tt.input)
+		if actual != tt.expected {
+			t.Errorf("wrong rendering for %q.\nexpected:\n%s\ngot:\n%s", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func renderRuntimeError(t *testing.T, input string) string {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	result := evaluator.Eval(program, object.NewEnvironment())
+
+	err, ok := result.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error for %q. got=%T (%+v)", input, result, result)
+	}
+
+	var out bytes.Buffer
+	PrintRuntimeError(&out, input, err)
+	return out.String()
+}
+
+func TestRuntimeErrorRendering(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let a = 1;\nlet b = a + true;",
+			"2:9: error: type mismatch: INTEGER + BOOLEAN\n" +
+				"   2 | let b = a + true;\n" +
+				"     |         ^~~~~~~~\n" +
+				"     = hint: both sides of the operator must be of the same type\n",
+		},
+		{
+			"let café = true;\nlet y = café + 1;",
+			"2:9: error: type mismatch: BOOLEAN + INTEGER\n" +
+				"   2 | let y = café + 1;\n" +
+				"     |         ^~~~~~~~\n" +
+				"     = hint: both sides of the operator must be of the same type\n",
+		},
+		{
+			"let y = 1 + nope * 2;",
+			"1:13: error: identifier not found: nope\n" +
+				"   1 | let y = 1 + nope * 2;\n" +
+				"     |             ^~~~\n" +
+				"     = hint: names must be bound with `let` before they are used\n",
+		},
+		{
+			"let a = [1, 2];\n\ta[1 + 1]",
+			"2:2: error: index out of range: 2 (length 2)\n" +
+				"   2 | \ta[1 + 1]\n" +
+				"     | \t^~~~~~~~\n",
+		},
+		{
+			"\"a\\tb\" - \"c\"",
+			"1:1: error: unknown operator: STRING - STRING\n" +
+				"   1 | \"a\\tb\" - \"c\"\n" +
+				"     | ^~~~~~~~~~~~\n",
+		},
+		{
+			// only the first line of an expression is underlined
+			"let f = fn() { 1 };\nf(\n1)",
+			"2:1: error: wrong number of arguments: want=0, got=1\n" +
+				"   2 | f(\n" +
+				"     | ^~\n",
+		},
+	}
+
+	for _, tt := range tests {
+		actual := renderRuntimeError(t, tt.input)
+		if actual != tt.expected {
+			t.Errorf("wrong rendering for %q.\nexpected:\n%s\ngot:\n%s", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestAssignErrorRendering(t *testing.T) {
+	input := "let a = 1;\nb = a;"
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	result := evaluator.Eval(program, object.NewEnvironment())
+
+	err, ok := result.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error. got=%T (%+v)", result, result)
+	}
+
+	var out bytes.Buffer
+	PrintRuntimeError(&out, input, err)
+
+	expected := "2:1: error: assignment to undeclared name: b\n" +
+		"   2 | b = a;\n" +
+		"     | ^~~~~\n" +
+		"     = hint: names must be bound with `let` before they can be assigned to\n"
+	if out.String() != expected {
+		t.Errorf("wrong rendering.\nexpected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
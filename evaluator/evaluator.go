@@ -0,0 +1,790 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"go-interpreter/ast"
+	"go-interpreter/object"
+)
+
+// there is only ever one true, false and null value,
+// so we reference these instead of allocating new objects
+var (
+	NULL  = &object.Null{}
+	TRUE  = &object.Boolean{Value: true}
+	FALSE = &object.Boolean{Value: false}
+)
+
+// maxIntegerBits : the largest result, in bits, that ** and << may give,
+// so that e.g. 2 ** 9000000000000000000 is an error rather than running
+// out of memory
+const maxIntegerBits = 1 << 24
+
+// Eval : evaluate node in the given environment, returning
+// the resulting object
+func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := eval(node, env)
+
+	// errors are positioned at the innermost node they came from,
+	// the nodes they are passed up through leave the position as is
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() && node != nil {
+		err.Pos = node.Pos()
+		err.End = node.End()
+	}
+
+	return result
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
+	switch node := node.(type) {
+
+	// statements
+	case *ast.Program:
+		return evalProgram(node, env)
+
+	case *ast.ExpressionStatement:
+		return Eval(node.Expression, env)
+
+	case *ast.BlockStatement:
+		return evalBlockStatement(node, env)
+
+	case *ast.LetStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		env.Set(node.Name.Value, val)
+
+	case *ast.ReturnStatement:
+		val := Eval(node.ReturnValue, env)
+		if isError(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+
+	// expressions
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInt{Value: node.Big}
+		}
+		return &object.Integer{Value: node.Value}
+
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
+
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+
+	case *ast.Boolean:
+		return nativeBoolToBooleanObject(node.Value)
+
+	case *ast.Identifier:
+		return evalIdentifier(node, env)
+
+	case *ast.PrefixExpression:
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalPrefixExpression(node.Operator, right)
+
+	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		right := Eval(node.Right, env)
+		if isError(right) {
+			return right
+		}
+		return evalInfixExpression(node.Operator, left, right)
+
+	case *ast.IfExpression:
+		return evalIfExpression(node, env)
+
+	case *ast.FunctionLiteral:
+		return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
+
+	case *ast.CallExpression:
+		function := Eval(node.Function, env)
+		if isError(function) {
+			return function
+		}
+		args := evalExpressions(node.Arguments, env)
+		if len(args) == 1 && isError(args[0]) {
+			return args[0]
+		}
+		return applyFunction(function, args)
+
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && isError(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+	}
+
+	return nil
+}
+
+// evalProgram : evaluate each statement of the program in order, stopping
+// early and unwrapping the value if we reach a return statement
+func evalProgram(program *ast.Program, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range program.Statements {
+		result = Eval(statement, env)
+
+		switch result := result.(type) {
+		case *object.ReturnValue:
+			return result.Value
+		case *object.Error:
+			return result
+		}
+	}
+
+	return result
+}
+
+// evalBlockStatement : unlike evalProgram, a return value is not unwrapped,
+// so that it bubbles up and stops evaluation of any enclosing blocks too
+func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
+	var result object.Object
+
+	for _, statement := range block.Statements {
+		result = Eval(statement, env)
+
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+
+	// an empty block, or one ending in a let statement, has no value
+	if result == nil {
+		return NULL
+	}
+
+	return result
+}
+
+func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
+	condition := Eval(ie.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return Eval(ie.Consequence, env)
+	} else if ie.Alternative != nil {
+		return Eval(ie.Alternative, env)
+	}
+
+	return NULL
+}
+
+// isTruthy : false and null are falsy, every other value is truthy
+func isTruthy(obj object.Object) bool {
+	switch obj {
+	case NULL:
+		return false
+	case FALSE:
+		return false
+	default:
+		return true
+	}
+}
+
+// evalExpressions : evaluate each expression from left to right,
+// stopping at (and returning only) the first error
+func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
+	var result []object.Object
+
+	for _, e := range exps {
+		evaluated := Eval(e, env)
+		if isError(evaluated) {
+			return []object.Object{evaluated}
+		}
+		result = append(result, evaluated)
+	}
+
+	return result
+}
+
+// applyFunction : evaluate the body of fn, with its parameters bound to
+// args in a new environment enclosed by the one fn was defined in
+func applyFunction(fn object.Object, args []object.Object) object.Object {
+	function, ok := fn.(*object.Function)
+	if !ok {
+		return newError("not a function: %s", fn.Type())
+	}
+
+	if len(args) != len(function.Parameters) {
+		return newError("wrong number of arguments: want=%d, got=%d", len(function.Parameters), len(args))
+	}
+
+	extendedEnv := object.NewEnclosedEnvironment(function.Env)
+	for i, param := range function.Parameters {
+		extendedEnv.Set(param.Value, args[i])
+	}
+
+	evaluated := Eval(function.Body, extendedEnv)
+	return unwrapReturnValue(evaluated)
+}
+
+// unwrapReturnValue : a return only stops evaluation of the function
+// it is in, so unwrap it before handing the value back to the caller
+func unwrapReturnValue(obj object.Object) object.Object {
+	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		return returnValue.Value
+	}
+
+	return obj
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.BIGINT_OBJ:
+		length := len(left.(*object.Array).Elements)
+		return newError("index out of range: %s (length %d)", index.Inspect(), length)
+	case left.Type() == object.ARRAY_OBJ:
+		return newError("array index must be INTEGER, got %s", index.Type())
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	elements := array.(*object.Array).Elements
+	idx := index.(*object.Integer).Value
+
+	if idx < 0 || idx >= int64(len(elements)) {
+		return newError("index out of range: %d (length %d)", idx, len(elements))
+	}
+
+	return elements[idx]
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	pairs := make(map[object.HashKey]object.HashPair)
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isError(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isError(value) {
+			return value
+		}
+
+		pairs[hashKey.HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return &object.Hash{Pairs: pairs}
+}
+
+// evalHashIndexExpression : looking up a key which is not
+// in the hash is not an error, and results in null
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	hashObject := hash.(*object.Hash)
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hashObject.Pairs[key.HashKey()]
+	if !ok {
+		return NULL
+	}
+
+	return pair.Value
+}
+
+// evalAssignExpression : x = v rebinds x in the scope it was bound in
+// with let, so a function can update a name of the scope it was defined
+// in, and a[i] = v or h[k] = v update the array or hash in place. The
+// result is the value assigned
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		current, ok := env.Get(target.Value)
+		if !ok {
+			return newError("assignment to undeclared name: %s", target.Value)
+		}
+		val := assignedValue(node, current, env)
+		if isError(val) {
+			return val
+		}
+		env.Assign(target.Value, val)
+		return val
+
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		return evalIndexAssignment(node, left, index, env)
+
+	default:
+		return newError("cannot assign to %s", node.Target.String())
+	}
+}
+
+// evalIndexAssignment : assign to left[index], an array index must
+// already be in range, while assigning to a key which is not in a
+// hash adds it
+func evalIndexAssignment(node *ast.AssignExpression, left, index object.Object, env *object.Environment) object.Object {
+	// indexing first reports the same errors as reading the element would
+	current := evalIndexExpression(left, index)
+	if isError(current) {
+		return current
+	}
+
+	val := assignedValue(node, current, env)
+	if isError(val) {
+		return val
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		left.Elements[index.(*object.Integer).Value] = val
+	case *object.Hash:
+		key := index.(object.Hashable)
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+	}
+
+	return val
+}
+
+// assignedValue : the value node assigns to a target holding current,
+// a compound assignment such as x += v applies its operator to current
+func assignedValue(node *ast.AssignExpression, current object.Object, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if isError(val) || node.Operator == "=" {
+		return val
+	}
+
+	return evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val)
+}
+
+func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
+	val, ok := env.Get(node.Value)
+	if !ok {
+		return newError("identifier not found: " + node.Value)
+	}
+
+	return val
+}
+
+func evalPrefixExpression(operator string, right object.Object) object.Object {
+	switch operator {
+	case "!":
+		return evalBangOperatorExpression(right)
+	case "-":
+		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalBitwiseNotExpression(right)
+	default:
+		return newError("unknown operator: %s%s", operator, right.Type())
+	}
+}
+
+// evalBitwiseNotExpression : ~x flips every bit of the two's
+// complement representation of x, so is equal to -x - 1
+func evalBitwiseNotExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^right.Value}
+	case *object.BigInt:
+		return newInteger(new(big.Int).Not(right.Value))
+	default:
+		return newError("unknown operator: ~%s", right.Type())
+	}
+}
+
+// evalBangOperatorExpression : false and null are falsy, anything
+// else is truthy, so !5 is false and !!5 is true
+func evalBangOperatorExpression(right object.Object) object.Object {
+	switch right {
+	case TRUE:
+		return FALSE
+	case FALSE:
+		return TRUE
+	case NULL:
+		return TRUE
+	default:
+		return FALSE
+	}
+}
+
+func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		// the negation of the smallest int64 is one too large for an int64
+		if right.Value == math.MinInt64 {
+			return newInteger(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.BigInt:
+		return newInteger(new(big.Int).Neg(right.Value))
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
+		return newError("unknown operator: -%s", right.Type())
+	}
+}
+
+// evalLogicalExpression : && and || only evaluate their right side if
+// the left does not decide the result, e.g. false && f() does not call
+// f. The result is true or false, by the truthiness of the sides
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	if isTruthy(left) == (node.Operator == "||") {
+		return nativeBoolToBooleanObject(isTruthy(left))
+	}
+
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
+	}
+
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
+func evalInfixExpression(operator string, left, right object.Object) object.Object {
+	switch {
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalIntegerInfixExpression(operator, left, right)
+	case isInteger(left) && isInteger(right):
+		return evalBigIntInfixExpression(operator, toBigInt(left), toBigInt(right))
+	// if either side is a float, so is the result, e.g. 1 + 0.5 is 1.5
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
+	case left.Type() != right.Type():
+		return newError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	// booleans are singletons, so comparing pointers compares values
+	case operator == "==":
+		return nativeBoolToBooleanObject(left == right)
+	case operator == "!=":
+		return nativeBoolToBooleanObject(left != right)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.Integer).Value
+	rightVal := right.(*object.Integer).Value
+
+	// results which would overflow an int64 are promoted to a BigInt
+	overflow := func() object.Object {
+		return evalBigIntInfixExpression(operator, big.NewInt(leftVal), big.NewInt(rightVal))
+	}
+
+	switch operator {
+	case "+":
+		sum := leftVal + rightVal
+		if (leftVal > 0 && rightVal > 0 && sum < 0) || (leftVal < 0 && rightVal < 0 && sum >= 0) {
+			return overflow()
+		}
+		return &object.Integer{Value: sum}
+	case "-":
+		diff := leftVal - rightVal
+		if (leftVal >= 0 && rightVal < 0 && diff < 0) || (leftVal < 0 && rightVal > 0 && diff >= 0) {
+			return overflow()
+		}
+		return &object.Integer{Value: diff}
+	case "*":
+		product := leftVal * rightVal
+		if leftVal != 0 && (product/leftVal != rightVal || leftVal == -1 && rightVal == math.MinInt64) {
+			return overflow()
+		}
+		return &object.Integer{Value: product}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return overflow()
+		}
+		return &object.Integer{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Integer{Value: leftVal % rightVal}
+	case "**", "<<":
+		// computed exactly, and demoted again if the result fits
+		return overflow()
+	case ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		return &object.Integer{Value: leftVal >> uint(rightVal)}
+	case "&":
+		return &object.Integer{Value: leftVal & rightVal}
+	case "|":
+		return &object.Integer{Value: leftVal | rightVal}
+	case "^":
+		return &object.Integer{Value: leftVal ^ rightVal}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalBigIntInfixExpression : arithmetic on integers where either side,
+// or the result, may not fit in an int64. Division truncates towards
+// zero, and the remainder has the sign of the left side, as they do for
+// an Integer. Bitwise operators act on the two's complement of the sides
+func evalBigIntInfixExpression(operator string, left, right *big.Int) object.Object {
+	switch operator {
+	case "+":
+		return newInteger(new(big.Int).Add(left, right))
+	case "-":
+		return newInteger(new(big.Int).Sub(left, right))
+	case "*":
+		return newInteger(new(big.Int).Mul(left, right))
+	case "/":
+		if right.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return newInteger(new(big.Int).Quo(left, right))
+	case "%":
+		if right.Sign() == 0 {
+			return newError("division by zero")
+		}
+		return newInteger(new(big.Int).Rem(left, right))
+	case "**":
+		// a negative power is a fraction, e.g. 2 ** -1 is 0.5
+		if right.Sign() < 0 {
+			return evalFloatInfixExpression(operator, newInteger(left), newInteger(right))
+		}
+		// any power of 0, 1 or -1 is small, however large the exponent
+		if left.CmpAbs(big.NewInt(1)) <= 0 {
+			return newInteger(new(big.Int).Exp(left, right, nil))
+		}
+		if !right.IsInt64() || right.Int64() > maxIntegerBits/int64(left.BitLen()) {
+			return newError("exponent too large: %s", right)
+		}
+		return newInteger(new(big.Int).Exp(left, right, nil))
+	case "<<":
+		if right.Sign() < 0 {
+			return newError("negative shift count: %s", right)
+		}
+		if left.Sign() == 0 {
+			return &object.Integer{Value: 0}
+		}
+		if !right.IsInt64() || right.Int64() > maxIntegerBits-int64(left.BitLen()) {
+			return newError("shift count too large: %s", right)
+		}
+		return newInteger(new(big.Int).Lsh(left, uint(right.Int64())))
+	case ">>":
+		if right.Sign() < 0 {
+			return newError("negative shift count: %s", right)
+		}
+		if !right.IsInt64() {
+			return newError("shift count too large: %s", right)
+		}
+		return newInteger(new(big.Int).Rsh(left, uint(right.Int64())))
+	case "&":
+		return newInteger(new(big.Int).And(left, right))
+	case "|":
+		return newInteger(new(big.Int).Or(left, right))
+	case "^":
+		return newInteger(new(big.Int).Xor(left, right))
+	case "<":
+		return nativeBoolToBooleanObject(left.Cmp(right) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(left.Cmp(right) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(left.Cmp(right) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(left.Cmp(right) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(left.Cmp(right) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(left.Cmp(right) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", bigIntType(left), operator, bigIntType(right))
+	}
+}
+
+// newInteger : the result of big integer arithmetic, demoted
+// to an Integer if it fits in an int64
+func newInteger(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInt{Value: value}
+}
+
+// bigIntType : the type of the object value would be stored in
+func bigIntType(value *big.Int) object.ObjectType {
+	return newInteger(value).Type()
+}
+
+// isInteger : whether obj is an Integer or a BigInt
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
+}
+
+// toBigInt : the value of an Integer or BigInt as a big.Int
+func toBigInt(obj object.Object) *big.Int {
+	if i, ok := obj.(*object.Integer); ok {
+		return big.NewInt(i.Value)
+	}
+	return obj.(*object.BigInt).Value
+}
+
+// evalFloatInfixExpression : arithmetic where either side is a float,
+// the other side is converted to a float if it is an integer
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := toFloat(left).Value
+	rightVal := toFloat(right).Value
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+	case "%":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: math.Mod(leftVal, rightVal)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// isNumber : whether obj is an integer of either size, or a float
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat : obj as a float, converting it if it is an integer, a BigInt
+// is rounded to the nearest float, or infinity if it is too large
+func toFloat(obj object.Object) *object.Float {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return &object.Float{Value: float64(obj.Value)}
+	case *object.BigInt:
+		f, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return &object.Float{Value: f}
+	}
+	return obj.(*object.Float)
+}
+
+func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
+
+	switch operator {
+	case "+":
+		return &object.String{Value: leftVal + rightVal}
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+	case "!=":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+func nativeBoolToBooleanObject(input bool) *object.Boolean {
+	if input {
+		return TRUE
+	}
+	return FALSE
+}
+
+func newError(format string, a ...interface{}) *object.Error {
+	return &object.Error{Message: fmt.Sprintf(format, a...)}
+}
+
+func isError(obj object.Object) bool {
+	if obj != nil {
+		return obj.Type() == object.ERROR_OBJ
+	}
+	return false
+}
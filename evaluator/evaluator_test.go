@@ -0,0 +1,836 @@
+package evaluator
+
+import (
+	"go-interpreter/lexer"
+	"go-interpreter/object"
+	"go-interpreter/parser"
+	"testing"
+)
+
+func testEval(input string) object.Object {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironment()
+
+	return Eval(program, env)
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, expected=%d", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func testBooleanObject(t *testing.T, obj object.Object, expected bool) bool {
+	result, ok := obj.(*object.Boolean)
+	if !ok {
+		t.Errorf("object is not Boolean. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%t, expected=%t", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func TestEvalIntegerExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5", 5},
+		{"10", 10},
+		{"-5", -5},
+		{"-10", -10},
+		{"5 + 5 + 5 + 5 - 10", 10},
+		{"2 * 2 * 2 * 2 * 2", 32},
+		{"-50 + 100 + -50", 0},
+		{"5 * 2 + 10", 20},
+		{"5 + 2 * 10", 25},
+		{"20 + 2 * -10", 0},
+		{"50 / 2 * 2 + 10", 60},
+		{"2 * (5 + 10)", 30},
+		{"3 * 3 * 3 + 10", 37},
+		{"3 * (3 * 3) + 10", 37},
+		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, expected=%g", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func TestEvalNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xff + 1", 256},
+		{"0o10 * 0b11", 24},
+		{"1_000 - 1", 999},
+		{"7 / 2", 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5", 1.5},
+		{"-2.5", -2.5},
+		{"1e3", 1000},
+		{"0.5 + 0.25", 0.75},
+		{"1.5 * 2.0 - 1.0", 2},
+		{"7.0 / 2", 3.5},
+		// an integer is converted to a float when the other side is one
+		{"1 + 0.5", 1.5},
+		{"0.5 + 1", 1.5},
+		{"3 * 1.5", 4.5},
+		{"7 / 2.0", 3.5},
+		{"-(1 - 1.5)", 0.5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		typ      object.ObjectType
+	}{
+		{"99999999999999999999", "99999999999999999999", object.BIGINT_OBJ},
+		{"-99999999999999999999", "-99999999999999999999", object.BIGINT_OBJ},
+		// promoted when the result would overflow an int64
+		{"9223372036854775807 + 1", "9223372036854775808", object.BIGINT_OBJ},
+		{"-9223372036854775807 - 2", "-9223372036854775809", object.BIGINT_OBJ},
+		{"1 - (-9223372036854775807 - 1)", "9223372036854775809", object.BIGINT_OBJ},
+		{"4294967296 * 4294967296", "18446744073709551616", object.BIGINT_OBJ},
+		{"-1 * (-9223372036854775807 - 1)", "9223372036854775808", object.BIGINT_OBJ},
+		{"(-9223372036854775807 - 1) * -1", "9223372036854775808", object.BIGINT_OBJ},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808", object.BIGINT_OBJ},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808", object.BIGINT_OBJ},
+		{"99999999999999999999 * 99999999999999999999", "9999999999999999999800000000000000000001", object.BIGINT_OBJ},
+		// demoted when the result fits in an int64 again
+		{"9223372036854775807 + 1 - 1", "9223372036854775807", object.INTEGER_OBJ},
+		{"99999999999999999999 - 99999999999999999998", "1", object.INTEGER_OBJ},
+		{"99999999999999999999 / 10000000000", "9999999999", object.INTEGER_OBJ},
+		{"-99999999999999999999 / 10000000000", "-9999999999", object.INTEGER_OBJ},
+		{"-(9223372036854775807 + 1)", "-9223372036854775808", object.INTEGER_OBJ},
+		{"let big = 2 * 9223372036854775807; big / 2", "9223372036854775807", object.INTEGER_OBJ},
+		// exact, where a float would lose precision
+		{"12345678901234567890123 + 1", "12345678901234567890124", object.BIGINT_OBJ},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		if evaluated.Type() != tt.typ || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - wrong result. expected=%s %s, got=%s %s",
+				tt.input, tt.typ, tt.expected, evaluated.Type(), evaluated.Inspect())
+		}
+	}
+}
+
+func TestBigIntegerComparisons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"99999999999999999999 > 1", true},
+		{"1 < 99999999999999999999", true},
+		{"-99999999999999999999 < 1", true},
+		{"99999999999999999999 == 99999999999999999999", true},
+		{"99999999999999999999 != 99999999999999999998", true},
+		{"9223372036854775807 + 1 == 9223372036854775808", true},
+		{"99999999999999999999 > 1.5", true},
+		{"99999999999999999999 < 1e21", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBigIntegerFloats(t *testing.T) {
+	evaluated := testEval("100000000000000000000 * 1.5")
+	testFloatObject(t, evaluated, 1.5e20)
+}
+
+func TestBigIntegerHashKeys(t *testing.T) {
+	evaluated := testEval(`let h = {99999999999999999999: "big", 1: "small"}; h[99999999999999999998 + 1] + h[99999999999999999999 - 99999999999999999998]`)
+
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+	if str.Value != "bigsmall" {
+		t.Errorf("wrong value. expected=%q, got=%q", "bigsmall", str.Value)
+	}
+}
+
+func TestIntegerOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"7 % 3", "1"},
+		{"-7 % 3", "-1"},
+		{"7 % -3", "1"},
+		{"(-9223372036854775807 - 1) % -1", "0"},
+		{"2 ** 10", "1024"},
+		{"2 ** 3 ** 2", "512"},
+		{"-2 ** 2", "-4"},
+		{"(-2) ** 3", "-8"},
+		{"10 ** 0", "1"},
+		{"2 ** 64", "18446744073709551616"},
+		{"2 ** 64 / 2 ** 60", "16"},
+		{"2 ** -1", "0.5"},
+		{"6 & 3", "2"},
+		{"6 | 3", "7"},
+		{"6 ^ 3", "5"},
+		{"~5", "-6"},
+		{"~-1", "0"},
+		{"1 << 10", "1024"},
+		{"1 << 64", "18446744073709551616"},
+		{"1 << 64 >> 60", "16"},
+		{"1024 >> 3", "128"},
+		{"-16 >> 2", "-4"},
+		{"-1 >> 100", "-1"},
+		{"1 >> 100", "0"},
+		{"(1 << 100) & ((1 << 100) - 1)", "0"},
+		{"(1 << 100) | 1", "1267650600228229401496703205377"},
+		{"((1 << 100) | 1) ^ (1 << 100)", "1"},
+		{"~(1 << 100)", "-1267650600228229401496703205377"},
+		{"-(1 << 100) % 7", "-2"},
+		// the results of ** and << are limited in size, except where they stay small
+		{"0 ** 9000000000000000000", "0"},
+		{"1 ** 9000000000000000000", "1"},
+		{"(-1) ** 9000000000000000000", "1"},
+		{"(-1) ** 9000000000000000001", "-1"},
+		{"1 ** 99999999999999999999", "1"},
+		{"0 << 9000000000000000000", "0"},
+		{"1 >> 9000000000000000000", "0"},
+		{"(1 << 16777000) >> 16777000", "1"},
+		{"(2 ** 100000) >> 99999", "2"},
+		{"1.5 ** 2", "2.25"},
+		{"2 ** 0.5 * 2 ** 0.5 > 1.99", "true"},
+		{"7.5 % 2", "1.5"},
+		{"-7.5 % 2", "-1.5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - wrong result. expected=%s, got=%s (%s)", tt.input, tt.expected, evaluated.Inspect(), evaluated.Type())
+		}
+	}
+}
+
+func TestComparisonOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"3 >= 2", true},
+		{"1.5 <= 1.5", true},
+		{"2 >= 2.5", false},
+		{"99999999999999999999 >= 99999999999999999999", true},
+		{"99999999999999999999 <= 1", false},
+		{"1 < 2 == 2 >= 1", true},
+		{"6 & 1 == 0", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"true || false", true},
+		{"1 && \"a\"", true},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 > 3", false},
+		{"!true || !false", true},
+		{"if (false) { 1 } || true", true},
+		// the right side is not evaluated once the result is known
+		{"false && missing", false},
+		{"true || missing", true},
+		{"false && 1 / 0", false},
+		{"let calls = [0]; let f = fn() { calls[99] }; true || f()", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestEvalMixedNumberComparisons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1.5 < 2.5", true},
+		{"1.5 > 2", false},
+		{"2 > 1.5", true},
+		{"1 == 1.0", true},
+		{"1.0 != 1", false},
+		{"0.1 + 0.2 == 0.3", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5", "1.5"},
+		{"2.0", "2.0"},
+		{"1 + 1.0", "2.0"},
+		{"1e21", "1e+21"},
+		{"-0.125", "-0.125"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - wrong Inspect(). expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestEvalBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"true", true},
+		{"false", false},
+		{"1 < 2", true},
+		{"1 > 2", false},
+		{"1 < 1", false},
+		{"1 == 1", true},
+		{"1 != 1", false},
+		{"1 == 2", false},
+		{"1 != 2", true},
+		{"1 < 2 == 2 > 1", true},
+		{"1 < 2 != 2 > 1", false},
+		{"true == true", true},
+		{"false == false", true},
+		{"true != false", true},
+		{"1 < 2 == true", true},
+		{"1 > 2 == true", false},
+		{`"a" == "a"`, true},
+		{`"a" == "b"`, false},
+		{`"a" != "b"`, true},
+		{`"a" != "a"`, false},
+		{`"foo" + "bar" == "foobar"`, true},
+		{`let a = "x"; let b = "x"; a == b`, true},
+		{"(1 < 2) == true", true},
+		{"(1 > 2) == false", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestBangOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"!true", false},
+		{"!false", true},
+		{"!!true", true},
+		{"!5", false},
+		{"!!5", true},
+		{"!(1 < 2)", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestIfElseExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if (true) { 10 }", 10},
+		{"if (false) { 10 }", nil},
+		{"if (1) { 10 }", 10},
+		{"if (1 < 2) { 10 }", 10},
+		{"if (1 > 2) { 10 }", nil},
+		{"if (1 > 2) { 10 } else { 20 }", 20},
+		{"if (1 < 2) { 10 } else { 20 }", 10},
+		{"if (1 > 2) { 10 } else if (2 > 1) { 20 } else { 30 }", 20},
+		{"if (1 > 2) { 10 } else if (2 > 3) { 20 } else { 30 }", 30},
+		{"if (1 > 2) { 10 } else if (2 > 3) { 20 }", nil},
+		{"if (true) { if (false) { 1 } else { 2 } }", 2},
+		{"if (true) { }", nil},
+		{"let x = if (true) { 5 } else { 6 }; x * 2", 10},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func testNullObject(t *testing.T, obj object.Object) bool {
+	if obj != NULL {
+		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
+		return false
+	}
+	return true
+}
+
+func TestReturnStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"return 10;", 10},
+		{"return 10; 9;", 10},
+		{"return 2 * 5; 9;", 10},
+		{"9; return 2 * 5; 9;", 10},
+		{"if (10 > 1) { if (10 > 1) { return 10; } return 1; }", 10},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"5 + true;", "type mismatch: INTEGER + BOOLEAN"},
+		{"5; true + 5; 5", "type mismatch: BOOLEAN + INTEGER"},
+		{"-true", "unknown operator: -BOOLEAN"},
+		{"true + false", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"5; true * false; 5", "unknown operator: BOOLEAN * BOOLEAN"},
+		{"if (10 > 1) { true + false; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"if (10 > 1) { if (10 > 1) { return true + false; } return 1; }", "unknown operator: BOOLEAN + BOOLEAN"},
+		{"foobar", "identifier not found: foobar"},
+		{"10 / 0", "division by zero"},
+		{"1.5 / 0", "division by zero"},
+		{"5 % 0", "division by zero"},
+		{"x = 1", "assignment to undeclared name: x"},
+		{"let f = fn() { y += 1 }; f()", "assignment to undeclared name: y"},
+		{"let x = 1; x += true", "type mismatch: INTEGER + BOOLEAN"},
+		{"let x = 1; x = missing", "identifier not found: missing"},
+		{"let arr = [1]; arr[1] = 2", "index out of range: 1 (length 1)"},
+		{"let arr = [1]; arr[-1] = 2", "index out of range: -1 (length 1)"},
+		{`let arr = [1]; arr["0"] = 2`, "array index must be INTEGER, got STRING"},
+		{`let h = {}; h["k"] += 1`, "type mismatch: NULL + INTEGER"},
+		{"let h = {}; h[fn(x) { x }] = 1", "unusable as hash key: FUNCTION"},
+		{"let s = 1; s[0] = 1", "index operator not supported: INTEGER"},
+		{"missing[0] = 1", "identifier not found: missing"},
+		{"5.5 % 0", "division by zero"},
+		{"99999999999999999999 % 0", "division by zero"},
+		{"1 << -1", "negative shift count: -1"},
+		{"1 >> -1", "negative shift count: -1"},
+		{"1 << 99999999999999999999", "shift count too large: 99999999999999999999"},
+		{"2 ** 99999999999999999999", "exponent too large: 99999999999999999999"},
+		{"1 << 9000000000000000000", "shift count too large: 9000000000000000000"},
+		{"-1 << 9000000000000000000", "shift count too large: 9000000000000000000"},
+		{"(1 << 16777215) << 1", "shift count too large: 1"},
+		{"2 ** 9000000000000000000", "exponent too large: 9000000000000000000"},
+		{"(-3) ** 9000000000000000000", "exponent too large: 9000000000000000000"},
+		{"(2 ** 100) ** 1000000", "exponent too large: 1000000"},
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER"},
+		{"1 | 1.5", "unknown operator: INTEGER | FLOAT"},
+		{"~1.5", "unknown operator: ~FLOAT"},
+		{"~true", "unknown operator: ~BOOLEAN"},
+		{"true <= false", "unknown operator: BOOLEAN <= BOOLEAN"},
+		{`"a" >= "b"`, "unknown operator: STRING >= STRING"},
+		{"true && missing", "identifier not found: missing"},
+		{"missing || true", "identifier not found: missing"},
+		{"1 / 0.0", "division by zero"},
+		{"1.5 + true", "type mismatch: FLOAT + BOOLEAN"},
+		{"99999999999999999999 + true", "type mismatch: BIGINT + BOOLEAN"},
+		{"99999999999999999999 / 0", "division by zero"},
+		{"[1, 2][99999999999999999999]", "index out of range: 99999999999999999999 (length 2)"},
+		{`"a" * 1.5`, "type mismatch: STRING * FLOAT"},
+		{"[1, 2][1.0]", "array index must be INTEGER, got FLOAT"},
+		{"{1.5: 1}", "unusable as hash key: FLOAT"},
+		{"5(1)", "not a function: INTEGER"},
+		{"[1, 2, 3][3]", "index out of range: 3 (length 3)"},
+		{"[1, 2, 3][-1]", "index out of range: -1 (length 3)"},
+		{"[][0]", "index out of range: 0 (length 0)"},
+		{"[1, 2, 3][true]", "array index must be INTEGER, got BOOLEAN"},
+		{"5[0]", "index operator not supported: INTEGER"},
+		{"[1, foo]", "identifier not found: foo"},
+		{`{"name": "Monkey"}[fn(x) { x }];`, "unusable as hash key: FUNCTION"},
+		{`{fn(x) { x }: 1}`, "unusable as hash key: FUNCTION"},
+		{`{[1]: 1}`, "unusable as hash key: ARRAY"},
+		{`"Hello" - "World"`, "unknown operator: STRING - STRING"},
+		{`"Hello" + 1`, "type mismatch: STRING + INTEGER"},
+		{"fn(x) { x }(1, 2)", "wrong number of arguments: want=1, got=2"},
+		{"fn(x) { y }(1)", "identifier not found: y"},
+		{"let f = fn() { let inner = 1; }; f(); inner", "identifier not found: inner"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestLetStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let a = 5; a;", 5},
+		{"let a = 5 * 5; a;", 25},
+		{"let a = 5; let b = a; b;", 5},
+		{"let a = 5; let b = a; let c = a + b + 5; c;", 15},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x = 1; x = 5; x;", 5},
+		{"let x = 1; x = 5;", 5},
+		{"let x = 1; x += 2; x;", 3},
+		{"let x = 10; x -= 2; x *= 3; x /= 4; x %= 4; x;", 2},
+		{"let x = 9223372036854775807; x += 1; x -= 1; x;", 9223372036854775807},
+		{"let arr = [1, 2, 3]; arr[1] = 5; arr[1];", 5},
+		{"let arr = [1, 2, 3]; arr[2] *= 10; arr[2] + arr[0];", 31},
+		{"let m = [[1, 2], [3, 4]]; m[1][0] += 10; m[1][0];", 13},
+		{`let h = {"k": 5}; h["k"] -= 2; h["k"];`, 3},
+		{`let h = {}; h["new"] = 7; h["new"];`, 7},
+		{`let h = {1: 1}; h[1] = 2; h[1] += 3; h[1];`, 5},
+		// arrays and hashes are updated in place, so the change is seen through other names
+		{"let a = [1]; let b = a; b[0] = 2; a[0];", 2},
+		// a function updates the binding of the scope it was defined in
+		{"let n = 0; let inc = fn() { n += 1 }; inc(); inc(); n;", 2},
+		{"let counter = fn() { let c = 0; fn() { c += 1; c } }; let next = counter(); next(); next();", 2},
+		// a binding of the same name in an inner scope hides the outer one
+		{"let x = 1; let f = fn() { let x = 2; x = 3; x }; f() + x;", 4},
+		{"let x = 1; let f = fn(x) { x = 10 }; f(2); x;", 1},
+		{"let x = 1; if (true) { x = 2 }; x;", 2},
+		{"let i = 0; let sum = 0; let loop = fn() { if (i < 5) { sum += i; i += 1; loop() } }; loop(); sum;", 10},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCyclicValues(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = [1]; a[0] = a; a", "[[...]]"},
+		{"let a = [1, 2]; a[1] = a; a", "[1, [...]]"},
+		{`let h = {"k": 1}; h["self"] = h; h`, `{k: 1, self: {...}}`},
+		{`let a = [0]; let h = {"a": a}; a[0] = h; a`, "[{a: [...]}]"},
+		// a value seen twice, but not inside itself, is printed in full
+		{"let b = [1]; [b, b]", "[[1], [1]]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("%q - wrong result. expected=%s, got=%s", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestFunctionObject(t *testing.T) {
+	input := "fn(x) { x + 2; };"
+
+	evaluated := testEval(input)
+	fn, ok := evaluated.(*object.Function)
+	if !ok {
+		t.Fatalf("object is not Function. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(fn.Parameters) != 1 {
+		t.Fatalf("function has wrong parameters. Parameters=%+v", fn.Parameters)
+	}
+
+	if fn.Parameters[0].String() != "x" {
+		t.Fatalf("parameter is not 'x'. got=%q", fn.Parameters[0])
+	}
+
+	expectedBody := "(x+2)"
+	if fn.Body.String() != expectedBody {
+		t.Fatalf("body is not %q. got=%q", expectedBody, fn.Body.String())
+	}
+}
+
+func TestFunctionApplication(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let identity = fn(x) { x; }; identity(5);", 5},
+		{"let identity = fn(x) { return x; }; identity(5);", 5},
+		{"let double = fn(x) { x * 2; }; double(5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5, 5);", 10},
+		{"let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));", 20},
+		{"fn(x) { x; }(5)", 5},
+		{"let f = fn(x) { if (x > 1) { return 1; } return 2; }; f(5) + f(0);", 3},
+		{"let x = 10; let f = fn(x) { x }; f(1) + x;", 11},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`
+		let adder = fn(x) { fn(y) { x + y } };
+		let addTwo = adder(2);
+		addTwo(3);`, 5},
+		{`
+		let add = fn(a) { fn(b) { fn(c) { a + b + c } } };
+		add(1)(2)(3);`, 6},
+		{`
+		let twice = fn(f, x) { f(f(x)) };
+		let inc = fn(x) { x + 1 };
+		twice(inc, 5);`, 7},
+		{`
+		let compose = fn(f, g) { fn(x) { g(f(x)) } };
+		let double = fn(x) { x * 2 };
+		let square = fn(x) { x * x };
+		compose(double, square)(3);`, 36},
+		{`
+		let fact = fn(n) { if (n < 2) { 1 } else { n * fact(n - 1) } };
+		fact(5);`, 120},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringLiteral(t *testing.T) {
+	input := `"Hello World!"`
+
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello World!" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestStringConcatenation(t *testing.T) {
+	input := `"Hello" + " " + "World!\n"`
+
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	if !ok {
+		t.Fatalf("object is not String. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if str.Value != "Hello World!\n" {
+		t.Errorf("String has wrong value. got=%q", str.Value)
+	}
+}
+
+func TestArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	if len(result.Elements) != 3 {
+		t.Fatalf("array has wrong num of elements. got=%d", len(result.Elements))
+	}
+
+	testIntegerObject(t, result.Elements[0], 1)
+	testIntegerObject(t, result.Elements[1], 4)
+	testIntegerObject(t, result.Elements[2], 6)
+}
+
+func TestArrayIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][2]", 3},
+		{"let i = 0; [1][i];", 1},
+		{"[1, 2, 3][1 + 1];", 3},
+		{"let myArray = [1, 2, 3]; myArray[2];", 3},
+		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
+		{"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]", 2},
+		{"[[1, 2], [3, 4]][1][0]", 3},
+		{"[fn(x) { x * 2 }][0](4)", 8},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashLiterals(t *testing.T) {
+	input := `let two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		true: 5,
+		false: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		TRUE.HashKey():                             5,
+		FALSE.HashKey():                            6,
+	}
+
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	}
+
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Pairs[expectedKey]
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+		}
+
+		testIntegerObject(t, pair.Value, expectedValue)
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`let h = {"name": "x", 1: true}; if (h[1]) { 1 } else { 0 }`, 1},
+		{`{"a": 1, "a": 2}["a"]`, 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+package format
+
+import (
+	"fmt"
+	"go-interpreter/ast"
+	"go-interpreter/lexer"
+	"go-interpreter/parser"
+	"go-interpreter/token"
+	"strings"
+	"unicode"
+)
+
+// Source : parse input, and return it in canonical form. Blocks are
+// indented with tabs, parentheses are only kept where they are needed
+// to preserve the meaning of an expression, and blank lines between
+// statements are kept (collapsing runs of them into one). Comments
+// are kept in the same order, each on its own line before the next
+// statement, or at the end of the line if it followed code on the same
+// line. A list with comments between its items, e.g. the pairs of a hash,
+// is printed with each item on its own line so the comments stay next to
+// them, and an error is returned for a comment anywhere else inside a
+// statement, as it would have to be moved. Formatting the output again
+// gives the same output. If input fails to parse, the parser errors are
+// returned
+func Source(input string) (string, error) {
+	return File("", input)
+}
+
+// File : format the contents of the named file, the
+// filename is included in the position of any errors
+func File(filename, input string) (string, error) {
+	p := parser.New(lexer.NewFileMode(filename, input, lexer.KeepComments))
+	program := p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 0 {
+		return "", errors.Err()
+	}
+
+	pr := &printer{src: input, comments: p.Comments()}
+	pr.statements(program.Statements, len(input), false)
+	if pr.err != nil {
+		return "", pr.err
+	}
+	return pr.out.String(), nil
+}
+
+// Node : node in canonical form, as Source would print it, but
+// without the blank lines of the source it was parsed from
+func Node(node ast.Node) string {
+	pr := &printer{}
+
+	switch node := node.(type) {
+	case *ast.Program:
+		pr.statements(node.Statements, 0, false)
+	case ast.Statement:
+		pr.statement(node)
+	case ast.Expression:
+		pr.expression(node)
+	}
+
+	return pr.out.String()
+}
+
+// printer : writes nodes in canonical form to out
+type printer struct {
+	out    strings.Builder
+	indent int    // the number of tabs at the start of each line
+	src    string // the source being formatted, if known
+
+	comments []token.Token // the comments of src not printed yet
+	// lineComment is set once a // or # comment has been printed
+	// on the current line, so nothing else can follow it
+	lineComment bool
+
+	err error // the first comment which could not be kept in place
+}
+
+// newline : end the current line, and indent the next one
+func (p *printer) newline() {
+	p.out.WriteByte('\n')
+	p.out.WriteString(strings.Repeat("\t", p.indent))
+	p.lineComment = false
+}
+
+// statements : print each statement on its own line, ending each with
+// a semicolon where it is needed, along with the comments before end,
+// the offset of the end of the list. In a block the last statement is
+// followed by the closing brace, so it never needs a semicolon
+func (p *printer) statements(stmts []ast.Statement, end int, block bool) {
+	started := false
+
+	// start a new line for the statement or comment at offset, the first
+	// line of a block starts after the {, but the first of the program
+	// needs no newline
+	line := func(offset int) {
+		if started && p.blankLineBefore(offset) {
+			p.out.WriteByte('\n')
+		}
+		if started || block {
+			p.newline()
+		}
+		started = true
+	}
+
+	for i, stmt := range stmts {
+		offset := stmt.Pos().Offset
+		p.trailingComments(offset)
+		for p.commentBefore(offset) {
+			line(p.comments[0].Pos.Offset)
+			p.comment()
+		}
+
+		line(offset)
+		p.statement(stmt)
+		p.misplacedComments(stmt.End().Offset)
+
+		last := i == len(stmts)-1
+		if block && last {
+			continue
+		}
+		if last {
+			p.semicolon(stmt, nil)
+		} else {
+			p.semicolon(stmt, stmts[i+1])
+		}
+	}
+
+	p.trailingComments(end)
+	for p.commentBefore(end) {
+		line(p.comments[0].Pos.Offset)
+		p.comment()
+	}
+
+	if !block && started {
+		p.out.WriteByte('\n')
+	}
+}
+
+// misplacedComments : record an error if there is a comment before offset
+// which has not been printed, e.g. between the operands of 1 + 2, as it
+// could only be printed later on, away from the code it was next to
+func (p *printer) misplacedComments(offset int) {
+	if p.err == nil && p.commentBefore(offset) {
+		p.err = fmt.Errorf("%s: comment cannot be kept in place, move it before or after the statement",
+			p.comments[0].Pos)
+	}
+}
+
+// commentBefore : whether the next comment is before offset
+func (p *printer) commentBefore(offset int) bool {
+	return len(p.comments) > 0 && p.comments[0].Pos.Offset < offset
+}
+
+// comment : print the next comment
+func (p *printer) comment() {
+	literal := p.comments[0].Literal
+	p.out.WriteString(literal)
+	p.lineComment = !strings.HasPrefix(literal, "/*")
+	p.comments = p.comments[1:]
+}
+
+// trailingComments : print the comments before offset that follow code
+// on the same line in the source, at the end of the current line
+func (p *printer) trailingComments(offset int) {
+	for p.commentBefore(offset) && !p.lineComment {
+		start := p.comments[0].Pos.Offset
+		before := strings.TrimRightFunc(p.src[:start], unicode.IsSpace)
+		if before == "" || strings.Contains(p.src[len(before):start], "\n") {
+			return
+		}
+		p.out.WriteByte(' ')
+		p.comment()
+	}
+}
+
+// semicolon : write a semicolon after stmt, unless it is an if
+// expression which cannot be continued by the statement after it.
+// Other statements always get one, so that e.g. a statement starting
+// with ( is not parsed as a call of the statement before it
+func (p *printer) semicolon(stmt, next ast.Statement) {
+	es, ok := stmt.(*ast.ExpressionStatement)
+	if ok {
+		if _, ok := es.Expression.(*ast.IfExpression); ok && !continues(next) {
+			return
+		}
+	}
+	p.out.WriteByte(';')
+}
+
+// continues : whether next starts with a token that could also be
+// parsed as an infix operator, joining it to the statement before it
+func continues(next ast.Statement) bool {
+	if next == nil {
+		return false
+	}
+	first := Node(next)
+	return first != "" && strings.ContainsRune("([-", rune(first[0]))
+}
+
+// blankLineBefore : whether there is a blank line in the
+// source between offset and the code or comment before it
+func (p *printer) blankLineBefore(offset int) bool {
+	if p.src == "" || offset > len(p.src) {
+		return false
+	}
+
+	before := strings.TrimRightFunc(p.src[:offset], unicode.IsSpace)
+	return strings.Count(p.src[len(before):offset], "\n") > 1
+}
+
+func (p *printer) statement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetStatement:
+		p.out.WriteString("let ")
+		p.out.WriteString(stmt.Name.Value)
+		p.out.WriteString(" = ")
+		p.expression(stmt.Value)
+	case *ast.ReturnStatement:
+		p.out.WriteString("return")
+		if stmt.ReturnValue != nil {
+			p.out.WriteByte(' ')
+			p.expression(stmt.ReturnValue)
+		}
+	case *ast.ExpressionStatement:
+		p.expression(stmt.Expression)
+	case *ast.BlockStatement:
+		p.block(stmt)
+	}
+}
+
+// block : print the statements of block indented between braces,
+// a block with no statements or comments is printed as {}
+func (p *printer) block(block *ast.BlockStatement) {
+	end := block.Rbrace.Offset
+	if len(block.Statements) == 0 && !p.commentBefore(end) {
+		p.out.WriteString("{}")
+		return
+	}
+
+	p.out.WriteByte('{')
+	p.indent++
+	p.statements(block.Statements, end, true)
+	p.indent--
+	p.newline()
+	p.out.WriteByte('}')
+}
+
+// precedence : how tightly exp binds, literals, identifiers and
+// expressions ending in a block can be used anywhere without
+// parentheses, so bind the most tightly of all
+func precedence(exp ast.Expression) int {
+	switch exp := exp.(type) {
+	case *ast.InfixExpression:
+		return parser.Precedence(exp.Token.Type)
+	case *ast.PrefixExpression:
+		return parser.PREFIX
+	case *ast.CallExpression:
+		return parser.CALL
+	case *ast.IndexExpression:
+		return parser.INDEX
+	case *ast.AssignExpression:
+		return parser.LOWEST
+	default:
+		return parser.INDEX + 1
+	}
+}
+
+// operand : print exp, in parentheses if it binds less tightly than min
+func (p *printer) operand(exp ast.Expression, min int) {
+	if precedence(exp) < min {
+		p.out.WriteByte('(')
+		p.expression(exp)
+		p.out.WriteByte(')')
+		return
+	}
+	p.expression(exp)
+}
+
+func (p *printer) expression(exp ast.Expression) {
+	switch exp := exp.(type) {
+	case *ast.Identifier:
+		p.out.WriteString(exp.Value)
+	case *ast.IntegerLiteral:
+		p.out.WriteString(exp.Token.Literal)
+	case *ast.FloatLiteral:
+		p.out.WriteString(exp.Token.Literal)
+	case *ast.Boolean:
+		p.out.WriteString(exp.Token.Literal)
+	case *ast.StringLiteral:
+		p.out.WriteString(quote(exp.Value))
+	case *ast.PrefixExpression:
+		p.out.WriteString(exp.Operator)
+		p.operand(exp.Right, parser.PREFIX)
+	case *ast.InfixExpression:
+		// most operators are left associative, so an operand on the right
+		// of the same precedence must be grouped, e.g. a - (b - c), and
+		// for right associative ones it is the left, e.g. (a ** b) ** c
+		prec := parser.Precedence(exp.Token.Type)
+		left, right := prec, prec+1
+		if parser.RightAssociative(exp.Token.Type) {
+			left, right = prec+1, prec
+		}
+		p.operand(exp.Left, left)
+		p.out.WriteString(" " + exp.Operator + " ")
+		p.operand(exp.Right, right)
+	case *ast.CallExpression:
+		// calls and indexes are both postfix, so can be chained
+		// in any order without parentheses, e.g. f(x)[0](y)
+		p.operand(exp.Function, parser.CALL)
+		p.out.WriteByte('(')
+		p.list(exp.Arguments, exp.Token.Pos, exp.Rparen)
+		p.out.WriteByte(')')
+	case *ast.IndexExpression:
+		p.operand(exp.Left, parser.CALL)
+		p.out.WriteByte('[')
+		p.expression(exp.Index)
+		p.out.WriteByte(']')
+	case *ast.ArrayLiteral:
+		p.out.WriteByte('[')
+		p.list(exp.Elements, exp.Token.Pos, exp.Rbracket)
+		p.out.WriteByte(']')
+	case *ast.HashLiteral:
+		starts := make([]int, len(exp.Pairs))
+		for i, pair := range exp.Pairs {
+			starts[i] = pair.Key.Pos().Offset
+		}
+
+		p.out.WriteByte('{')
+		p.items(starts, exp.Token.Pos, exp.Rbrace, func(i int) {
+			p.expression(exp.Pairs[i].Key)
+			p.out.WriteString(": ")
+			p.expression(exp.Pairs[i].Value)
+		})
+		p.out.WriteByte('}')
+	case *ast.FunctionLiteral:
+		params := make([]string, len(exp.Parameters))
+		for i, param := range exp.Parameters {
+			params[i] = param.Value
+		}
+		p.out.WriteString("fn(" + strings.Join(params, ", ") + ") ")
+		p.misplacedComments(exp.Body.Token.Pos.Offset)
+		p.block(exp.Body)
+	case *ast.IfExpression:
+		p.ifExpression(exp)
+	case *ast.AssignExpression:
+		p.expression(exp.Target)
+		p.out.WriteString(" " + exp.Operator + " ")
+		p.expression(exp.Value)
+	}
+}
+
+// ifExpression : print an if expression, with an alternative holding
+// only another if expression printed as else if
+func (p *printer) ifExpression(exp *ast.IfExpression) {
+	p.out.WriteString("if (")
+	p.expression(exp.Condition)
+	p.out.WriteString(") ")
+	p.misplacedComments(exp.Consequence.Token.Pos.Offset)
+	p.block(exp.Consequence)
+
+	if exp.Alternative == nil {
+		return
+	}
+
+	p.out.WriteString(" else ")
+	if stmts := exp.Alternative.Statements; len(stmts) == 1 {
+		if es, ok := stmts[0].(*ast.ExpressionStatement); ok {
+			if nested, ok := es.Expression.(*ast.IfExpression); ok {
+				p.ifExpression(nested)
+				return
+			}
+		}
+	}
+	p.block(exp.Alternative)
+}
+
+// list : print exps separated by commas, as items does, between
+// the brackets at open and close
+func (p *printer) list(exps []ast.Expression, open, close token.Pos) {
+	starts := make([]int, len(exps))
+	for i, exp := range exps {
+		starts[i] = exp.Pos().Offset
+	}
+
+	p.items(starts, open, close, func(i int) {
+		p.expression(exps[i])
+	})
+}
+
+// items : print the items of a list between the brackets at open and
+// close, separated by commas, starts holding the offset of each item. If
+// there are comments between the brackets, each item is printed on its
+// own line, with the comments before it on the lines above, and those
+// after it on the same line, e.g.
+//
+//	{
+//		"a": 1, // one
+//		// two
+//		"b": 2
+//	}
+func (p *printer) items(starts []int, open, close token.Pos, item func(i int)) {
+	if !p.commentBefore(close.Offset) || p.comments[0].Pos.Offset < open.Offset {
+		for i := range starts {
+			if i > 0 {
+				p.out.WriteString(", ")
+			}
+			item(i)
+		}
+		return
+	}
+
+	p.indent++
+	for i, start := range starts {
+		if i > 0 {
+			p.out.WriteByte(',')
+		}
+		p.trailingComments(start)
+		for p.commentBefore(start) {
+			p.newline()
+			p.comment()
+		}
+		p.newline()
+		item(i)
+	}
+	p.trailingComments(close.Offset)
+	for p.commentBefore(close.Offset) {
+		p.newline()
+		p.comment()
+	}
+	p.indent--
+	p.newline()
+}
+
+// quote : s as a string literal, using the escape sequences the
+// lexer understands for quotes, backslashes and control characters
+func quote(s string) string {
+	var out strings.Builder
+
+	out.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"':
+			out.WriteString(`\"`)
+		case r == '\\':
+			out.WriteString(`\\`)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&out, `\u{%X}`, r)
+		default:
+			out.WriteRune(r)
+		}
+	}
+	out.WriteByte('"')
+
+	return out.String()
+}
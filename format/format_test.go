@@ -0,0 +1,216 @@
+package format
+
+import (
+	"go-interpreter/lexer"
+	"go-interpreter/parser"
+	"strings"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=5", "let x = 5;\n"},
+		{"return x", "return x;\n"},
+		{"a+b*c", "a + b * c;\n"},
+		{"(a+b)*c", "(a + b) * c;\n"},
+		{"((a*b))+c", "a * b + c;\n"},
+		{"a-(b-c)", "a - (b - c);\n"},
+		{"(a-b)-c", "a - b - c;\n"},
+		{"-(a+b)", "-(a + b);\n"},
+		{"-(-a)", "--a;\n"},
+		{"!(a==b)", "!(a == b);\n"},
+		{"(a<b)==(c>d)", "a < b == c > d;\n"},
+		{"(-a)[0]", "(-a)[0];\n"},
+		{"x=5", "x = 5;\n"},
+		{"x+=(1)", "x += 1;\n"},
+		{"(arr)[i]=(v*2)", "arr[i] = v * 2;\n"},
+		{"h[\"k\"]-=2", "h[\"k\"] -= 2;\n"},
+		{"let f = fn(){n*=2}", "let f = fn() {\n\tn *= 2\n};\n"},
+		{"a**(b**c)", "a ** b ** c;\n"},
+		{"(a**b)**c", "(a ** b) ** c;\n"},
+		{"-(a**b)", "-a ** b;\n"},
+		{"(-a)**b", "(-a) ** b;\n"},
+		{"(a||b)&&(c||d)", "(a || b) && (c || d);\n"},
+		{"(a&&b)||c", "a && b || c;\n"},
+		{"(a&b)==(c|d)", "a & b == c | d;\n"},
+		{"a<<(b+c)", "a << b + c;\n"},
+		{"(a<<b)+c", "(a << b) + c;\n"},
+		{"~(a&b)%c", "~(a & b) % c;\n"},
+		{"-a[0]", "-a[0];\n"},
+		{"(f)(1,2)", "f(1, 2);\n"},
+		{"(f[0])(1)", "f[0](1);\n"},
+		{"(fn(x){x})(1)", "fn(x) {\n\tx\n}(1);\n"},
+		{"add(a+b, c*d)[1]", "add(a + b, c * d)[1];\n"},
+		{"[1,2 , 3]", "[1, 2, 3];\n"},
+		{"0xFF+1_000*2.5e-3", "0xFF + 1_000 * 2.5e-3;\n"},
+		{`{"one":1,"two":2}`, "{\"one\": 1, \"two\": 2};\n"},
+		{`"a\"b\\c\nd\te"`, "\"a\\\"b\\\\c\\nd\\te\";\n"},
+		{"fn(){}", "fn() {};\n"},
+		{"let add=fn(a,b){a+b}", "let add = fn(a, b) {\n\ta + b\n};\n"},
+		{"fn(x){let y=x*2; return y}(5)", "fn(x) {\n\tlet y = x * 2;\n\treturn y\n}(5);\n"},
+		{
+			"if(x<y){x}else{y}",
+			"if (x < y) {\n\tx\n} else {\n\ty\n}\n",
+		},
+		{
+			"if (a) { 1 } else if (b) { 2 } else { 3 }",
+			"if (a) {\n\t1\n} else if (b) {\n\t2\n} else {\n\t3\n}\n",
+		},
+		{
+			"let f = fn(x) { if (x) { fn(y) { y } } }",
+			"let f = fn(x) {\n\tif (x) {\n\t\tfn(y) {\n\t\t\ty\n\t\t}\n\t}\n};\n",
+		},
+		// an if followed by a statement that could continue it keeps its semicolon
+		{"if (a) { 1 }; (b + 1) * 2", "if (a) {\n\t1\n};\n(b + 1) * 2;\n"},
+		{"if (a) { 1 }; -1", "if (a) {\n\t1\n};\n-1;\n"},
+		{"if (a) { 1 }; b", "if (a) {\n\t1\n}\nb;\n"},
+		// blank lines between statements are kept, runs of them collapsed
+		{"let a = 1;\n\n\n\nlet b = 2;\nlet c = 3;", "let a = 1;\n\nlet b = 2;\nlet c = 3;\n"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		formatted, err := Source(tt.input)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", tt.input, err)
+		}
+		if formatted != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, formatted)
+		}
+	}
+}
+
+func TestSourceIdempotent(t *testing.T) {
+	inputs := []string{
+		"let fib = fn(n) { if (n < 2) { return n; } fib(n - 1) + fib(n - 2) };\n\nfib(10)",
+		"let m = {\"a\": [1, 2, (3 + 4) * 5], true: fn() { -(-1) }};\nm[\"a\"][2]",
+		"let x = if (a) { 1 } else if (b) { 2 } else { 3 };\nif (x == 1) { x }\n(x)",
+		"map([1, 2], fn(x) { x * 2 })",
+	}
+
+	for _, input := range inputs {
+		once, err := Source(input)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", input, err)
+		}
+		twice, err := Source(once)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", once, err)
+		}
+		if once != twice {
+			t.Errorf("formatting is not idempotent.\nonce=%q\ntwice=%q", once, twice)
+		}
+
+		// the formatted program must mean the same as the original
+		if expected, got := parse(t, input), parse(t, once); expected != got {
+			t.Errorf("formatting changed the program.\nexpected=%q\ngot=%q", expected, got)
+		}
+	}
+}
+
+func TestSourceComments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"// header\nlet x=1", "// header\nlet x = 1;\n"},
+		{"// header\n\n\nlet x=1", "// header\n\nlet x = 1;\n"},
+		{"let x=1 // one\nlet y=2 # two", "let x = 1; // one\nlet y = 2; # two\n"},
+		{"let x=1\n// about y\nlet y=2", "let x = 1;\n// about y\nlet y = 2;\n"},
+		{"let x=1\n\n/* about y */\n\nlet y=2", "let x = 1;\n\n/* about y */\n\nlet y = 2;\n"},
+		{"let x=1;\n// the end", "let x = 1;\n// the end\n"},
+		{"// only a comment", "// only a comment\n"},
+		{
+			"let f = fn(x) { // doc\n// before\nx * 2 // after\n// last\n}",
+			"let f = fn(x) { // doc\n\t// before\n\tx * 2 // after\n\t// last\n};\n",
+		},
+		{"fn() { /* todo */ }", "fn() { /* todo */\n};\n"},
+		{
+			"if (a) { 1 } // one\nelse { /* two */ 2 }",
+			"if (a) {\n\t1\n} else { // one\n\t/* two */\n\t2\n}\n",
+		},
+		// a list with comments inside is printed one item per line
+		{"add(1, /* two */ 2)", "add(\n\t1, /* two */\n\t2\n);\n"},
+		{"let h = {\n// first\n\"a\": 1}", "let h = {\n\t// first\n\t\"a\": 1\n};\n"},
+		{
+			"let x = {\n  \"a\": 1, // one\n  \"b\": 2\n};",
+			"let x = {\n\t\"a\": 1, // one\n\t\"b\": 2\n};\n",
+		},
+		{
+			"let a = [ // numbers\n1,\n// two\n[2, 3], 4 # four\n]; a",
+			"let a = [ // numbers\n\t1,\n\t// two\n\t[2, 3],\n\t4 # four\n];\na;\n",
+		},
+		{
+			"let f = fn() {\nlet h = {\"k\": [1, # one\n2]}\n}",
+			"let f = fn() {\n\tlet h = {\n\t\t\"k\": [\n\t\t\t1, # one\n\t\t\t2\n\t\t]\n\t}\n};\n",
+		},
+	}
+
+	for _, tt := range tests {
+		formatted, err := Source(tt.input)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", tt.input, err)
+		}
+		if formatted != tt.expected {
+			t.Errorf("Source(%q) wrong.\nexpected=%q\ngot=%q", tt.input, tt.expected, formatted)
+		}
+
+		twice, err := Source(formatted)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", formatted, err)
+		}
+		if twice != formatted {
+			t.Errorf("formatting %q is not idempotent.\nonce=%q\ntwice=%q", tt.input, formatted, twice)
+		}
+	}
+}
+
+func TestSourceMisplacedComments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1 + // one\n2;", "c.mk:1:13: comment cannot be kept in place"},
+		{"let y = 1;\nif (a /* a */) { 1 }", "c.mk:2:7: comment cannot be kept in place"},
+		{"let f = fn(a, /* b */ b) { a };", "c.mk:1:15: comment cannot be kept in place"},
+		{"let z = /* z */ [1];", "c.mk:1:9: comment cannot be kept in place"},
+		{"fn() {\n\tx[/* i */ 0]\n}", "c.mk:2:4: comment cannot be kept in place"},
+	}
+
+	for _, tt := range tests {
+		_, err := File("c.mk", tt.input)
+		if err == nil {
+			t.Errorf("expected an error for %q", tt.input)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	_, err := File("broken.mk", "let = 5;")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if !strings.HasPrefix(err.Error(), "broken.mk:1:5: expected next token to be IDENT") {
+		t.Errorf("wrong error. got=%q", err)
+	}
+	if _, ok := err.(parser.ErrorList); !ok {
+		t.Errorf("error is not parser.ErrorList. got=%T", err)
+	}
+}
+
+// parse : the fully parenthesised form of input
+func parse(t *testing.T, input string) string {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 0 {
+		t.Fatalf("parse(%q) failed: %s", input, errors)
+	}
+	return program.String()
+}
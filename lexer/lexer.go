@@ -1,35 +1,95 @@
 package lexer
 
 import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"go-interpreter/token"
 )
 
-// Lexer : lexer struct
+// Lexer : lexer struct. The input is decoded as UTF-8, a byte which is
+// not part of a valid UTF-8 sequence is returned as an ILLEGAL token
+// positioned at that byte, whether it is found in code, a string or a
+// comment. Identifiers start with a Unicode letter or underscore, which
+// may be followed by more letters, underscores, and Unicode decimal
+// digits, e.g. café, π, x1, and 变量. Numbers are only written with the
+// ASCII digits 0-9, see readNumber
 type Lexer struct {
 	input        string
 	position     int // current position in input (points to char)
 	readPosition int // current reading position in input (after current char)
 	// this is used to peek to the next char, if needed e.g.
 	// to see if a second '=' comes after an '=' char
-	ch byte // current char under examination
+	ch rune // current char under examination
 
 	// position is what we just read, readPosition is what we will read next
+
+	filename string // name of the file being lexed, used in positions
+	line     int    // line of the current char, starting at 1
+	column   int    // column of the current char, starting at 1
+
+	mode Mode
 }
 
+// Mode : options controlling what the lexer produces
+type Mode uint
+
+const (
+	// KeepComments : comments are returned as COMMENT tokens, rather
+	// than being skipped like whitespace. The literal of the token is
+	// the whole comment, including the // # or /* */ around it
+	KeepComments Mode = 1 << iota
+)
+
 // New : create new lexer
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFile("", input)
+}
+
+// NewFile : create new lexer for the contents of the named file,
+// the filename is included in the position of every token
+func NewFile(filename, input string) *Lexer {
+	return NewFileMode(filename, input, 0)
+}
+
+// NewFileMode : create new lexer for the contents of the named
+// file, with the options in mode, e.g. to keep comments
+func NewFileMode(filename, input string, mode Mode) *Lexer {
+	l := &Lexer{input: input, filename: filename, line: 1, mode: mode}
 	l.readChar()
 	return l
 }
 
 // NextToken : get the next token
 func (l *Lexer) NextToken() token.Token {
+	tok := l.nextToken()
+	// every token has been read up to the char after it
+	tok.End = l.pos()
+	return tok
+}
+
+func (l *Lexer) nextToken() token.Token {
 	var tok token.Token
 
 	// read characters until we have reached a non-whitespace character
 	l.skipWhiteSpace()
 
+	// the token starts at the current char, this must be read before
+	// any of the cases below advance past it
+	pos := l.pos()
+
+	// comments are skipped like whitespace, unless they are being kept
+	for l.atComment() {
+		comment := l.readComment(pos)
+		if comment.Type == token.ILLEGAL || l.mode&KeepComments != 0 {
+			return comment
+		}
+		l.skipWhiteSpace()
+		pos = l.pos()
+	}
+
 	// different cases for character we encounter
 	switch l.ch {
 	case '=':
@@ -51,6 +111,8 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -61,18 +123,36 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		tok = l.twoCharToken(token.GT, map[rune]token.TokenType{'=': token.GTE, '>': token.SHR})
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		tok = l.twoCharToken(token.LT, map[rune]token.TokenType{'=': token.LTE, '<': token.SHL})
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		tok = l.twoCharToken(token.SLASH, map[rune]token.TokenType{'=': token.SLASH_ASSIGN})
 	case '*':
-		tok = newToken(token.TIMES, l.ch)
+		tok = l.twoCharToken(token.TIMES, map[rune]token.TokenType{'*': token.POWER, '=': token.TIMES_ASSIGN})
+	case '%':
+		tok = l.twoCharToken(token.MODULO, map[rune]token.TokenType{'=': token.MODULO_ASSIGN})
+	case '&':
+		tok = l.twoCharToken(token.BITAND, map[rune]token.TokenType{'&': token.AND})
+	case '|':
+		tok = l.twoCharToken(token.BITOR, map[rune]token.TokenType{'|': token.OR})
+	case '^':
+		tok = newToken(token.BITXOR, l.ch)
+	case '~':
+		tok = newToken(token.BITNOT, l.ch)
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		tok = l.twoCharToken(token.PLUS, map[rune]token.TokenType{'=': token.PLUS_ASSIGN})
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		tok = l.twoCharToken(token.MINUS, map[rune]token.TokenType{'=': token.MINUS_ASSIGN})
+	case '"':
+		tok = l.readString(pos)
+		// an invalid byte in the string is reported where it is
+		pos = tok.Pos
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -84,60 +164,265 @@ func (l *Lexer) NextToken() token.Token {
 			// lookup the identifier, return special types for
 			// keywords (if,else..), otherwise IDENT
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Pos = pos
 			return tok
 		} else if isNumber(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = token.INT
+			tok.Literal, tok.Type = l.readNumber()
+			tok.Pos = pos
 			return tok
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			// the raw text of the char, which may be an invalid byte
+			tok = token.Token{Type: token.ILLEGAL, Literal: l.input[l.position:l.readPosition]}
 		}
 	}
+	tok.Pos = pos
 	// read next character
 	l.readChar()
 	return tok
 }
 
 // newToken : create new token of type t, and with literal value v
-func newToken(t token.TokenType, v byte) token.Token {
+func newToken(t token.TokenType, v rune) token.Token {
 	return token.Token{Type: t, Literal: string(v)}
 }
 
-// returns whether a character is a letter, or underscore
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// twoCharToken : a token of the type in second for the current char and
+// the one after it, e.g. <= or <<, or of type single if the next char is
+// not in second, leaving the lexer at the last char of the token
+func (l *Lexer) twoCharToken(single token.TokenType, second map[rune]token.TokenType) token.Token {
+	if t, ok := second[l.peekChar()]; ok {
+		ch := l.ch
+		l.readChar()
+		return token.Token{Type: t, Literal: string(ch) + string(l.ch)}
+	}
+	return newToken(single, l.ch)
+}
+
+// returns whether a character is a letter, or underscore,
+// which an identifier can start with
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
-func isNumber(ch byte) bool {
+// isIdentChar : whether a character can be part of an identifier
+// after the first, as a letter, underscore or decimal digit
+func isIdentChar(ch rune) bool {
+	return isLetter(ch) || isNumber(ch) || ch >= utf8.RuneSelf && unicode.IsDigit(ch)
+}
+
+func isNumber(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
 // peekChar : used to peek one character ahead, for cases of 2 char
 // patterns, e.g. ==, !=
-func (l *Lexer) peekChar() byte {
+func (l *Lexer) peekChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
 	}
-	return l.input[l.readPosition]
+	r, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return r
+}
+
+// invalid : whether the current char is a byte which is not valid UTF-8.
+// It is decoded as utf8.RuneError, but unlike a U+FFFD written in the
+// input, is only one byte long
+func (l *Lexer) invalid() bool {
+	return l.ch == utf8.RuneError && l.readPosition-l.position == 1
 }
 
 // readIdentifier : gets all of the characters of an identifier
 func (l *Lexer) readIdentifier() string {
 	position := l.position
 	// increment position pointer while the
-	// character is still a letter
-	for isLetter(l.ch) {
+	// character can still be part of the identifier
+	for isIdentChar(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber : reads an integer or float literal. Integers are decimal,
+// or hex, octal or binary with a 0x, 0o or 0b prefix. Floats are decimal,
+// with a fraction (1.5), an exponent (1e9), or both (2.5e-3). Digits can
+// be separated by underscores, e.g. 1_000_000. A decimal integer or
+// float cannot start with a 0 followed by more digits, e.g. 010 or 01.5
+// is invalid, while 0 and 0.5 are fine. Any letters, digits and
+// underscores following the number are read as part of it, so that e.g.
+// 0b102 or 12abc is a single literal the parser reports as invalid,
+// rather than a number followed by an identifier
+func (l *Lexer) readNumber() (string, token.TokenType) {
 	position := l.position
-	for isNumber(l.ch) {
+	var tokenType token.TokenType = token.INT
+
+	prefixed := l.ch == '0' && strings.ContainsRune("xXoObB", l.peekChar())
+	if prefixed {
+		l.readChar()
+		l.readChar()
+	}
+
+	for {
+		switch {
+		case isAlphanumeric(l.ch):
+			// the sign of an exponent, e.g. 1e-5
+			exponent := !prefixed && (l.ch == 'e' || l.ch == 'E')
+			l.readChar()
+			if exponent && (l.ch == '+' || l.ch == '-') && isNumber(l.peekChar()) {
+				l.readChar()
+			}
+			if exponent {
+				tokenType = token.FLOAT
+			}
+		case l.ch == '.' && !prefixed && tokenType == token.INT && isNumber(l.peekChar()):
+			tokenType = token.FLOAT
+			l.readChar()
+		default:
+			return l.input[position:l.position], tokenType
+		}
+	}
+}
+
+// isAlphanumeric : whether ch is an ASCII letter, digit or underscore
+func isAlphanumeric(ch rune) bool {
+	return isNumber(ch) || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+}
+
+// readString : reads the characters between a pair of double quotes
+// starting at pos, replacing escape sequences with the characters they
+// represent. Returns an ILLEGAL token with the raw text read so far if
+// the string is not terminated, or contains an invalid escape sequence,
+// and one holding the first invalid byte if it is not valid UTF-8
+func (l *Lexer) readString(pos token.Pos) token.Token {
+	var out strings.Builder
+	var invalid token.Pos // the first invalid byte, if any
+
+	illegal := func() token.Token {
+		return token.Token{Type: token.ILLEGAL, Literal: l.input[pos.Offset:l.position], Pos: pos}
+	}
+
+	for {
+		l.readChar()
+		switch {
+		case l.ch == '"':
+			if invalid.IsValid() {
+				return token.Token{Type: token.ILLEGAL, Literal: l.input[invalid.Offset : invalid.Offset+1], Pos: invalid}
+			}
+			return token.Token{Type: token.STRING, Literal: out.String(), Pos: pos}
+		case l.ch == 0:
+			return illegal()
+		case l.ch == '\\':
+			l.readChar()
+			switch l.ch {
+			case 'n':
+				out.WriteByte('\n')
+			case 't':
+				out.WriteByte('\t')
+			case '"':
+				out.WriteByte('"')
+			case '\\':
+				out.WriteByte('\\')
+			case 'u':
+				r, ok := l.readUnicodeEscape()
+				if !ok {
+					return illegal()
+				}
+				out.WriteRune(r)
+			default:
+				return illegal()
+			}
+		case l.invalid():
+			if !invalid.IsValid() {
+				invalid = l.pos()
+			}
+		default:
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// readUnicodeEscape : reads the {XXXX} part of a \u{XXXX} escape,
+// where XXXX is the hex code point of the character, e.g. \u{1F600}
+func (l *Lexer) readUnicodeEscape() (rune, bool) {
+	if l.peekChar() != '{' {
+		return 0, false
+	}
+	l.readChar()
+
+	position := l.position + 1
+	for l.peekChar() != '}' {
+		if l.peekChar() == 0 || l.peekChar() == '"' {
+			return 0, false
+		}
+		l.readChar()
+	}
+	digits := l.input[position:l.readPosition]
+	l.readChar()
+
+	if len(digits) == 0 || len(digits) > 6 {
+		return 0, false
+	}
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, false
+	}
+
+	return rune(code), true
+}
+
+// atComment : whether a comment starts at the current char,
+// either a // or # line comment, or a /* block comment
+func (l *Lexer) atComment() bool {
+	return l.ch == '#' || l.ch == '/' && (l.peekChar() == '/' || l.peekChar() == '*')
+}
+
+// readComment : reads the comment starting at pos, leaving the lexer
+// at the char after it. A line comment runs up to the end of the line,
+// not including the newline. Block comments can be nested, so
+// /* a /* b */ c */ is a single comment. Returns an ILLEGAL token with
+// the text read if a block comment is not closed before the end of the
+// input, and one holding the first invalid byte if it is not valid UTF-8
+func (l *Lexer) readComment(pos token.Pos) token.Token {
+	var invalid token.Pos // the first invalid byte, if any
+
+	comment := func() token.Token {
+		if invalid.IsValid() {
+			return token.Token{Type: token.ILLEGAL, Literal: l.input[invalid.Offset : invalid.Offset+1], Pos: invalid}
+		}
+		return token.Token{Type: token.COMMENT, Literal: l.input[pos.Offset:l.position], Pos: pos}
+	}
+
+	if l.ch != '/' || l.peekChar() != '*' {
+		for l.ch != '\n' && l.ch != 0 {
+			if l.invalid() && !invalid.IsValid() {
+				invalid = l.pos()
+			}
+			l.readChar()
+		}
+		return comment()
+	}
+
+	depth := 0
+	for {
+		switch {
+		case l.ch == 0:
+			return token.Token{Type: token.ILLEGAL, Literal: l.input[pos.Offset:l.position], Pos: pos}
+		case l.ch == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return comment()
+			}
+		case l.invalid() && !invalid.IsValid():
+			invalid = l.pos()
+		}
 		l.readChar()
 	}
-	return l.input[position:l.position]
 }
 
 // skipWhiteSpace : lexer method which skips whitespace
@@ -148,13 +433,37 @@ func (l *Lexer) skipWhiteSpace() {
 	}
 }
 
-// readChar : read next character
+// pos : position of the current char
+func (l *Lexer) pos() token.Pos {
+	return token.Pos{
+		Filename: l.filename,
+		Line:     l.line,
+		Column:   l.column,
+		Offset:   l.position,
+	}
+}
+
+// readChar : read the next character, decoding it from UTF-8. Columns
+// count characters rather than bytes, an invalid byte counting as one
 func (l *Lexer) readChar() {
+	// moving past a newline starts a new line
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	// the column stops advancing once we have read past the end
+	if l.readPosition <= len(l.input) {
+		l.column++
+	}
+
+	l.position = l.readPosition
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+		l.readPosition++
+		return
 	}
-	l.position = l.readPosition
-	l.readPosition++
+
+	r, width := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	l.ch = r
+	l.readPosition += width
 }
@@ -81,7 +81,7 @@ func TestNextToken2(t *testing.T) {
 	x + y;
 	};
 	let result = add(five, ten);
-	!-/*5;
+	!-/ *5;
 	5 < 10 > 5; if else if else
 	`
 
@@ -213,3 +213,491 @@ func TestNextToken3(t *testing.T) {
 		}
 	}
 }
+
+func TestStrings(t *testing.T) {
+	input := `"foobar" "foo bar" "" "a\nb\tc" "say \"hi\"" "back\\slash" "\u{48}\u{49}" "\u{1F600}"`
+
+	tests := []struct {
+		expextedType    token.TokenType
+		expextedLiteral string
+	}{
+		{token.STRING, "foobar"},
+		{token.STRING, "foo bar"},
+		{token.STRING, ""},
+		{token.STRING, "a\nb\tc"},
+		{token.STRING, `say "hi"`},
+		{token.STRING, `back\slash`},
+		{token.STRING, "HI"},
+		{token.STRING, "\U0001F600"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expextedType {
+			t.Fatalf("tests[%d] - token type wrong, expected=%q, actual=%q", i, tt.expextedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expextedLiteral {
+			t.Fatalf("tests[%d] - literal wrong, expected=%q, actual=%q", i, tt.expextedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestIllegalStrings(t *testing.T) {
+	tests := []string{
+		`"unterminated`,
+		`"bad \q escape"`,
+		`"\u{}"`,
+		`"\u{110000}"`,
+		`"\u{zz}"`,
+		`"\u41"`,
+		`"trailing \`,
+	}
+
+	for _, input := range tests {
+		l := New(input)
+		tok := l.NextToken()
+
+		if tok.Type != token.ILLEGAL {
+			t.Errorf("%s - token type wrong, expected=%q, actual=%q", input, token.ILLEGAL, tok.Type)
+		}
+	}
+}
+
+func TestBracketsAndColon(t *testing.T) {
+	input := `[1, 2][0]; {"a": 1}`
+
+	tests := []struct {
+		expextedType    token.TokenType
+		expextedLiteral string
+	}{
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expextedType {
+			t.Fatalf("tests[%d] - token type wrong, expected=%q, actual=%q", i, tt.expextedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expextedLiteral {
+			t.Fatalf("tests[%d] - literal wrong, expected=%q, actual=%q", i, tt.expextedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  x + \"s\";\n\n}"
+
+	tests := []struct {
+		expextedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+		expectedOffset int
+	}{
+		{token.LET, 1, 1, 0},
+		{token.IDENT, 1, 5, 4},
+		{token.ASSIGN, 1, 7, 6},
+		{token.INT, 1, 9, 8},
+		{token.SEMICOLON, 1, 10, 9},
+		{token.IDENT, 2, 3, 13},
+		{token.PLUS, 2, 5, 15},
+		{token.STRING, 2, 7, 17},
+		{token.SEMICOLON, 2, 10, 20},
+		{token.RBRACE, 4, 1, 23},
+		{token.EOF, 4, 2, 24},
+	}
+	l := NewFile("test.mk", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expextedType {
+			t.Fatalf("tests[%d] - token type wrong, expected=%q, actual=%q", i, tt.expextedType, tok.Type)
+		}
+
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn || tok.Pos.Offset != tt.expectedOffset {
+			t.Fatalf("tests[%d] - position wrong, expected=%d:%d (offset %d), actual=%d:%d (offset %d)",
+				i, tt.expectedLine, tt.expectedColumn, tt.expectedOffset, tok.Pos.Line, tok.Pos.Column, tok.Pos.Offset)
+		}
+
+		if tok.Pos.Filename != "test.mk" {
+			t.Fatalf("tests[%d] - filename wrong, expected=%q, actual=%q", i, "test.mk", tok.Pos.Filename)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+let x = 5; # hash comment
+/* block /* nested */ still comment */ x / 2
+/**/x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.LET, "let", 2, 1},
+		{token.IDENT, "x", 2, 5},
+		{token.ASSIGN, "=", 2, 7},
+		{token.INT, "5", 2, 9},
+		{token.SEMICOLON, ";", 2, 10},
+		{token.IDENT, "x", 3, 40},
+		{token.SLASH, "/", 3, 42},
+		{token.INT, "2", 3, 44},
+		{token.IDENT, "x", 4, 5},
+		{token.EOF, "", 4, 6},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - wrong position. expected=%d:%d, got=%s",
+				i, tt.expectedLine, tt.expectedColumn, tok.Pos)
+		}
+	}
+}
+
+func TestKeepComments(t *testing.T) {
+	input := "// one\nlet x = /* two /* three */ */ 5; # four\n"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.COMMENT, "// one"},
+		{token.LET, "let"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.COMMENT, "/* two /* three */ */"},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "# four"},
+		{token.EOF, ""},
+	}
+
+	l := NewFileMode("", input, KeepComments)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestUnterminatedComment(t *testing.T) {
+	tests := []string{
+		"/* never closed",
+		"/* outer /* inner */ but not outer",
+		"/*/",
+	}
+
+	for _, input := range tests {
+		for _, mode := range []Mode{0, KeepComments} {
+			l := NewFileMode("", "1 "+input, mode)
+			l.NextToken()
+			illegal := l.NextToken()
+
+			if illegal.Type != token.ILLEGAL || illegal.Literal != input {
+				t.Errorf("%q - wrong token. expected=ILLEGAL %q, got=%q %q", input, input, illegal.Type, illegal.Literal)
+			}
+			if illegal.Pos.Column != 3 {
+				t.Errorf("%q - wrong column. expected=3, got=%d", input, illegal.Pos.Column)
+			}
+		}
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	input := "let café = \"☕ 😀\";\nπ * r2 + 变量_1 - _x9٣"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.LET, "let", 1, 1},
+		{token.IDENT, "café", 1, 5},
+		{token.ASSIGN, "=", 1, 10},
+		{token.STRING, "☕ 😀", 1, 12},
+		{token.SEMICOLON, ";", 1, 17},
+		{token.IDENT, "π", 2, 1},
+		{token.TIMES, "*", 2, 3},
+		{token.IDENT, "r2", 2, 5},
+		{token.PLUS, "+", 2, 8},
+		{token.IDENT, "变量_1", 2, 10},
+		{token.MINUS, "-", 2, 15},
+		{token.IDENT, "_x9٣", 2, 17},
+		{token.EOF, "", 2, 21},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - wrong position. expected=%d:%d, got=%s",
+				i, tt.expectedLine, tt.expectedColumn, tok.Pos)
+		}
+	}
+}
+
+func TestIllegalRunes(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedColumn  int
+		expectedOffset  int
+	}{
+		// digits other than 0-9 can't start a number or identifier
+		{"x + ٣", "٣", 5, 4},
+		{"x + €", "€", 5, 4},
+		// invalid UTF-8 is reported at the invalid byte
+		{"x + \xff", "\xff", 5, 4},
+		{"é + \xc3", "\xc3", 5, 5},
+		{"x + \"ab\xffc\xfe\"", "\xff", 8, 7},
+		{"x + // note \xff\n", "\xff", 13, 12},
+		{"x + /* é \xff */ 1", "\xff", 10, 10},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []Mode{0, KeepComments} {
+			l := NewFileMode("", tt.input, mode)
+			l.NextToken()
+			l.NextToken()
+			tok := l.NextToken()
+
+			if tok.Type != token.ILLEGAL || tok.Literal != tt.expectedLiteral {
+				t.Errorf("%q - wrong token. expected=ILLEGAL %q, got=%q %q",
+					tt.input, tt.expectedLiteral, tok.Type, tok.Literal)
+			}
+			if tok.Pos.Column != tt.expectedColumn || tok.Pos.Offset != tt.expectedOffset {
+				t.Errorf("%q - wrong position. expected column=%d offset=%d, got column=%d offset=%d",
+					tt.input, tt.expectedColumn, tt.expectedOffset, tok.Pos.Column, tok.Pos.Offset)
+			}
+		}
+	}
+}
+
+func TestReplacementCharacterIsValid(t *testing.T) {
+	// an encoded U+FFFD is valid UTF-8, unlike the invalid bytes it
+	// is used to represent when decoding
+	l := New("\"�\"")
+	tok := l.NextToken()
+
+	if tok.Type != token.STRING || tok.Literal != "�" {
+		t.Errorf("wrong token. expected=STRING %q, got=%q %q", "�", tok.Type, tok.Literal)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"42", token.INT, "42"},
+		{"1_000_000", token.INT, "1_000_000"},
+		{"0x1F", token.INT, "0x1F"},
+		{"0XdeAD_beef", token.INT, "0XdeAD_beef"},
+		{"0o17", token.INT, "0o17"},
+		{"0b1010_1010", token.INT, "0b1010_1010"},
+		{"1.5", token.FLOAT, "1.5"},
+		{"0.25", token.FLOAT, "0.25"},
+		{"1_000.000_1", token.FLOAT, "1_000.000_1"},
+		{"1e9", token.FLOAT, "1e9"},
+		{"2.5E-3", token.FLOAT, "2.5E-3"},
+		{"6.02e+23", token.FLOAT, "6.02e+23"},
+		// the exponent of a hex number is just another digit
+		{"0x1e5", token.INT, "0x1e5"},
+		// invalid numbers are read whole, for the parser to report
+		{"0b102", token.INT, "0b102"},
+		{"12abc", token.INT, "12abc"},
+		{"3e", token.FLOAT, "3e"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("%q - wrong token. expected=%q %q, got=%q %q",
+				tt.input, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("%q - expected EOF after the number, got=%q %q", tt.input, next.Type, next.Literal)
+		}
+	}
+}
+
+func TestNumbersFollowedByOperators(t *testing.T) {
+	input := "1.5.2 1-2 1e-2 1e-x 5.method"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "1.5"},
+		{token.ILLEGAL, "."},
+		{token.INT, "2"},
+		{token.INT, "1"},
+		{token.MINUS, "-"},
+		{token.INT, "2"},
+		{token.FLOAT, "1e-2"},
+		{token.FLOAT, "1e"},
+		{token.MINUS, "-"},
+		{token.IDENT, "x"},
+		{token.INT, "5"},
+		{token.ILLEGAL, "."},
+		{token.IDENT, "method"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	input := "<= >= < > % ** * && & || | ^ ~ << >> <<= >>> *** &&& a&&b"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LTE, "<="},
+		{token.GTE, ">="},
+		{token.LT, "<"},
+		{token.GT, ">"},
+		{token.MODULO, "%"},
+		{token.POWER, "**"},
+		{token.TIMES, "*"},
+		{token.AND, "&&"},
+		{token.BITAND, "&"},
+		{token.OR, "||"},
+		{token.BITOR, "|"},
+		{token.BITXOR, "^"},
+		{token.BITNOT, "~"},
+		{token.SHL, "<<"},
+		{token.SHR, ">>"},
+		// the longest operator at each point is taken
+		{token.SHL, "<<"},
+		{token.ASSIGN, "="},
+		{token.SHR, ">>"},
+		{token.GT, ">"},
+		{token.POWER, "**"},
+		{token.TIMES, "*"},
+		{token.AND, "&&"},
+		{token.BITAND, "&"},
+		{token.IDENT, "a"},
+		{token.AND, "&&"},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestAssignOperators(t *testing.T) {
+	input := "x += 1; x -= 2; x *= 3; x /= 4; x %= 5; x = -=1; x+=-1; x == = ** ="
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.TIMES_ASSIGN, "*="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.MODULO_ASSIGN, "%="},
+		{token.INT, "5"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.MINUS, "-"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.EQ, "=="},
+		{token.ASSIGN, "="},
+		{token.POWER, "**"},
+		{token.ASSIGN, "="},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
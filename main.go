@@ -1,19 +1,204 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"go-interpreter/diag"
+	"go-interpreter/evaluator"
+	"go-interpreter/format"
+	"go-interpreter/lexer"
+	"go-interpreter/object"
+	"go-interpreter/parser"
 	"go-interpreter/repl"
+	"io"
 	"os"
 	"os/user"
 )
 
+const usage = `usage:
+  go-interpreter                     start the REPL, or run the program piped to stdin
+  go-interpreter run file [args...]  run the program in file
+  go-interpreter -e 'expr' [args...] run the program given as an argument
+  go-interpreter fmt [-w] [file...]  print the files in canonical form, or
+                                     stdin if no files are given, -w writes
+                                     the result back to each file instead
+
+args are bound to the array args in the program, and the value of the
+program is printed if it is not null. The exit code is 1 if the program
+fails to parse or produces an error, and 2 if the usage is wrong.
+`
+
 func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, isTerminal(os.Stdin)))
+}
+
+// run : runs the command line given by args, returning the exit code.
+// With no arguments, the REPL is started if stdin is a terminal, and
+// otherwise the program piped to stdin is run
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, interactive bool) int {
+	if len(args) == 0 {
+		if interactive {
+			greet(stdout)
+			repl.Start(stdin, stdout)
+			return 0
+		}
+
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "error reading stdin: %s\n", err)
+			return 1
+		}
+		return execute("<stdin>", string(input), nil, stdout, stderr)
+	}
+
+	switch args[0] {
+	case "run":
+		if len(args) < 2 {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		input, err := os.ReadFile(args[1])
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %s\n", err)
+			return 1
+		}
+		return execute(args[1], string(input), args[2:], stdout, stderr)
+	case "-e":
+		if len(args) < 2 {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		return execute("-e", args[1], args[2:], stdout, stderr)
+	case "fmt":
+		return formatFiles(args[1:], stdin, stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+}
+
+// execute : parse and evaluate the program in input, reporting any
+// errors to stderr, and printing the value of the program to stdout
+func execute(filename, input string, args []string, stdout, stderr io.Writer) int {
+	p := parser.New(lexer.NewFile(filename, input))
+	program := p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 0 {
+		diag.PrintParseErrors(stderr, input, errors)
+		return 1
+	}
+
+	env := object.NewEnvironment()
+	env.Set("args", argsArray(args))
+
+	result := evaluator.Eval(program, env)
+	if err, ok := result.(*object.Error); ok {
+		diag.PrintRuntimeError(stderr, input, err)
+		return 1
+	}
+
+	if result != nil && result != evaluator.NULL {
+		fmt.Fprintln(stdout, result.Inspect())
+	}
+	return 0
+}
+
+// formatFiles : runs the fmt command, printing each file in canonical
+// form, or writing it back to the file if -w is given
+func formatFiles(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	write := len(args) > 0 && args[0] == "-w"
+	if write {
+		args = args[1:]
+	}
+
+	if len(args) == 0 {
+		if write {
+			fmt.Fprint(stderr, usage)
+			return 2
+		}
+		input, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "error reading stdin: %s\n", err)
+			return 1
+		}
+		return formatSource("<stdin>", string(input), stdout, stderr)
+	}
+
+	code := 0
+	for _, filename := range args {
+		input, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %s\n", err)
+			code = 1
+			continue
+		}
+
+		if !write {
+			if formatSource(filename, string(input), stdout, stderr) != 0 {
+				code = 1
+			}
+			continue
+		}
+
+		var out bytes.Buffer
+		if formatSource(filename, string(input), &out, stderr) != 0 {
+			code = 1
+			continue
+		}
+		if out.String() == string(input) {
+			continue
+		}
+		if err := os.WriteFile(filename, out.Bytes(), 0644); err != nil {
+			fmt.Fprintf(stderr, "error: %s\n", err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// formatSource : print input in canonical form to stdout,
+// or its parse errors to stderr if it cannot be parsed
+func formatSource(filename, input string, stdout, stderr io.Writer) int {
+	formatted, err := format.File(filename, input)
+	if err != nil {
+		if errors, ok := err.(parser.ErrorList); ok {
+			diag.PrintParseErrors(stderr, input, errors)
+		} else {
+			fmt.Fprintf(stderr, "error: %s\n", err)
+		}
+		return 1
+	}
+
+	fmt.Fprint(stdout, formatted)
+	return 0
+}
+
+func argsArray(args []string) *object.Array {
+	elements := make([]object.Object, len(args))
+	for i, arg := range args {
+		elements[i] = &object.String{Value: arg}
+	}
+	return &object.Array{Elements: elements}
+}
+
+// isTerminal : whether f is a terminal, rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func greet(out io.Writer) {
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
 	}
-	fmt.Printf("Hello %s, Welcome to Aashray's go interpreter!\n", user.Username)
-	fmt.Printf("Type any legal commands, you may have to read through" +
+	fmt.Fprintf(out, "Hello %s, Welcome to Aashray's go interpreter!\n", user.Username)
+	fmt.Fprintf(out, "Type any legal commands, you may have to read through"+
 		"my code to guess the language semantics >:)\n")
-	repl.Start(os.Stdin, os.Stdout)
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.mk")
+	if err := os.WriteFile(script, []byte("let add = fn(a, b) { a + b };\nadd(1, 2);\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(dir, "broken.mk")
+	if err := os.WriteFile(broken, []byte("let x = 1;\nlet = 2;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args           []string
+		stdin          string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{[]string{"run", script}, "", 0, "3\n", ""},
+		{[]string{"run", script, "a", "b"}, "", 0, "3\n", ""},
+		{[]string{"run", broken}, "", 1, "", broken + ":2:5: error: expected next token to be IDENT, got = instead"},
+		{[]string{"run", filepath.Join(dir, "missing.mk")}, "", 1, "", "no such file"},
+		{[]string{"run"}, "", 2, "", "usage:"},
+		{[]string{"-e", "1 + 2 * 3"}, "", 0, "7\n", ""},
+		{[]string{"-e", "args[1]", "x", "y"}, "", 0, "y\n", ""},
+		{[]string{"-e", "let x = 1;"}, "", 0, "", ""},
+		{[]string{"-e", "if (false) { 1 }"}, "", 0, "", ""},
+		{[]string{"-e", "1 + true"}, "", 1, "", "-e:1:1: error: type mismatch: INTEGER + BOOLEAN"},
+		{[]string{"-e", `let café = "☕"; café + "!"`}, "", 0, "☕!\n", ""},
+		{[]string{"-e"}, "", 2, "", "usage:"},
+		{nil, "let x = 5;\nx * 2", 0, "10\n", ""},
+		{nil, "x", 1, "", "<stdin>:1:1: error: identifier not found: x"},
+		{[]string{"fmt"}, "let x=1+2*3", 0, "let x = 1 + 2 * 3;\n", ""},
+		{[]string{"fmt", script}, "", 0, "let add = fn(a, b) {\n\ta + b\n};\nadd(1, 2);\n", ""},
+		{[]string{"fmt", broken}, "", 1, "", broken + ":2:5: error: expected next token to be IDENT"},
+		{[]string{"fmt", "-w"}, "", 2, "", "usage:"},
+		{[]string{"bogus"}, "", 2, "", `unknown command "bogus"`},
+		{[]string{"--help"}, "", 0, "usage:", ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+
+		code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr, false)
+
+		if code != tt.expectedCode {
+			t.Errorf("%v: wrong exit code. expected=%d, got=%d (stderr=%q)", tt.args, tt.expectedCode, code, stderr.String())
+		}
+
+		if !strings.HasPrefix(stdout.String(), tt.expectedStdout) || (tt.expectedStdout == "" && stdout.Len() != 0) {
+			t.Errorf("%v: wrong stdout. expected=%q, got=%q", tt.args, tt.expectedStdout, stdout.String())
+		}
+
+		if !strings.Contains(stderr.String(), tt.expectedStderr) || (tt.expectedStderr == "" && stderr.Len() != 0) {
+			t.Errorf("%v: wrong stderr. expected=%q, got=%q", tt.args, tt.expectedStderr, stderr.String())
+		}
+	}
+}
+
+func TestFormatWrite(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "script.mk")
+	if err := os.WriteFile(file, []byte("let x=(1+2)*3\nx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"fmt", "-w", file}, strings.NewReader(""), &stdout, &stderr, false); code != 0 {
+		t.Fatalf("wrong exit code. expected=0, got=%d (stderr=%q)", code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no output with -w, got=%q", stdout.String())
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "let x = (1 + 2) * 3;\nx;\n"
+	if string(data) != expected {
+		t.Errorf("wrong file contents. expected=%q, got=%q", expected, string(data))
+	}
+}
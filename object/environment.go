@@ -0,0 +1,73 @@
+package object
+
+import "sort"
+
+// Environment : maps identifiers bound with let statements
+// to the values they were bound to. An environment may be
+// enclosed by an outer one, e.g. the scope a function was
+// defined in, which is searched when a name is not found
+type Environment struct {
+	store map[string]Object
+	outer *Environment
+}
+
+// NewEnvironment : create new, empty environment
+func NewEnvironment() *Environment {
+	s := make(map[string]Object)
+	return &Environment{store: s, outer: nil}
+}
+
+// NewEnclosedEnvironment : create new, empty environment
+// which is enclosed by outer
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	env := NewEnvironment()
+	env.outer = outer
+	return env
+}
+
+// Get : get the value bound to name, searching the enclosing
+// environments if it is not bound in this one
+func (e *Environment) Get(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	if !ok && e.outer != nil {
+		obj, ok = e.outer.Get(name)
+	}
+	return obj, ok
+}
+
+// Set : bind name to val in this environment, returning val
+func (e *Environment) Set(name string, val Object) Object {
+	e.store[name] = val
+	return val
+}
+
+// Assign : rebind name to val in the innermost environment it
+// is bound in, which may enclose this one, returning false if
+// name is not bound in any of them
+func (e *Environment) Assign(name string, val Object) (Object, bool) {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// Names : the names bound in this environment and those
+// enclosing it, sorted and without duplicates
+func (e *Environment) Names() []string {
+	seen := make(map[string]bool)
+	for env := e; env != nil; env = env.outer {
+		for name := range env.store {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
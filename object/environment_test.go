@@ -0,0 +1,103 @@
+package object
+
+import "testing"
+
+func TestEnclosedEnvironment(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	outer.Set("y", &Integer{Value: 2})
+
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("y", &Integer{Value: 3})
+
+	tests := []struct {
+		env      *Environment
+		name     string
+		expected int64
+	}{
+		{inner, "x", 1},
+		{inner, "y", 3},
+		{outer, "y", 2},
+	}
+
+	for _, tt := range tests {
+		obj, ok := tt.env.Get(tt.name)
+		if !ok {
+			t.Fatalf("%s not found", tt.name)
+		}
+		if obj.(*Integer).Value != tt.expected {
+			t.Errorf("%s has wrong value. got=%d, expected=%d", tt.name, obj.(*Integer).Value, tt.expected)
+		}
+	}
+
+	if _, ok := outer.Get("z"); ok {
+		t.Errorf("expected z to be unbound")
+	}
+}
+
+func TestEnvironmentAssign(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+	outer.Set("y", &Integer{Value: 2})
+
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("y", &Integer{Value: 3})
+
+	// x is only bound in outer, so is rebound there
+	if _, ok := inner.Assign("x", &Integer{Value: 4}); !ok {
+		t.Fatalf("x not found")
+	}
+	// y is bound in both, so only the inner binding changes
+	if _, ok := inner.Assign("y", &Integer{Value: 5}); !ok {
+		t.Fatalf("y not found")
+	}
+
+	tests := []struct {
+		env      *Environment
+		name     string
+		expected int64
+	}{
+		{outer, "x", 4},
+		{inner, "x", 4},
+		{outer, "y", 2},
+		{inner, "y", 5},
+	}
+
+	for _, tt := range tests {
+		obj, ok := tt.env.Get(tt.name)
+		if !ok {
+			t.Fatalf("%s not found", tt.name)
+		}
+		if obj.(*Integer).Value != tt.expected {
+			t.Errorf("%s has wrong value. got=%d, expected=%d", tt.name, obj.(*Integer).Value, tt.expected)
+		}
+	}
+
+	if _, ok := inner.Assign("z", &Integer{Value: 6}); ok {
+		t.Errorf("expected assigning unbound z to fail")
+	}
+	if _, ok := inner.Get("z"); ok {
+		t.Errorf("expected z to still be unbound")
+	}
+}
+
+func TestEnvironmentNames(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("b", &Integer{Value: 1})
+	outer.Set("a", &Integer{Value: 2})
+
+	inner := NewEnclosedEnvironment(outer)
+	inner.Set("c", &Integer{Value: 3})
+	inner.Set("a", &Integer{Value: 4})
+
+	names := inner.Names()
+	expected := []string{"a", "b", "c"}
+	if len(names) != len(expected) {
+		t.Fatalf("wrong names. expected=%v, got=%v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("names[%d] wrong. expected=%q, got=%q", i, expected[i], names[i])
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package object
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-interpreter/ast"
+	"go-interpreter/token"
+)
+
+type ObjectType string
+
+const (
+	INTEGER_OBJ      = "INTEGER"
+	BIGINT_OBJ       = "BIGINT"
+	FLOAT_OBJ        = "FLOAT"
+	BOOLEAN_OBJ      = "BOOLEAN"
+	STRING_OBJ       = "STRING"
+	NULL_OBJ         = "NULL"
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	ERROR_OBJ        = "ERROR"
+	FUNCTION_OBJ     = "FUNCTION"
+	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
+)
+
+// Object : every value produced while evaluating a program
+// is represented by a type implementing the Object interface
+type Object interface {
+	Type() ObjectType
+	Inspect() string
+}
+
+// Hashable : implemented by the objects which can be used as hash keys
+type Hashable interface {
+	HashKey() HashKey
+}
+
+// HashKey : the key an object is stored under in a hash, objects
+// of the same type and with the same value have the same HashKey
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Integer : wraps the int64 value of an evaluated integer
+type Integer struct {
+	Value int64
+}
+
+func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+// BigInt : an integer too large to fit in an Integer, which the result
+// of integer arithmetic is promoted to when it would overflow. A BigInt
+// always holds a value outside of the range of int64, results which fit
+// are stored as an Integer instead
+type BigInt struct {
+	Value *big.Int
+}
+
+func (b *BigInt) Type() ObjectType { return BIGINT_OBJ }
+func (b *BigInt) Inspect() string  { return b.Value.String() }
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(b.Value.String()))
+
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+// Float : wraps the float64 value of an evaluated float. Floats are
+// not hashable, as values which print the same may not be equal
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
+// Inspect : the shortest representation of the value, always with a
+// fraction or exponent, so that e.g. 2.0 is not shown as an integer
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+// Boolean : wraps the value of an evaluated boolean
+type Boolean struct {
+	Value bool
+}
+
+func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
+func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+
+	if b.Value {
+		value = 1
+	}
+
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+// String : wraps the value of an evaluated string
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+// Null : represents the absence of a value
+type Null struct{}
+
+func (n *Null) Type() ObjectType { return NULL_OBJ }
+func (n *Null) Inspect() string  { return "null" }
+
+// ReturnValue : wraps the value of a return statement, so that the
+// evaluator knows to stop evaluating the statements that follow it
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+// Error : an error produced at runtime, e.g. a type mismatch, which
+// stops evaluation in the same way as a return value. Pos and End
+// span the innermost expression the error was produced by
+type Error struct {
+	Message string
+	Pos     token.Pos
+	End     token.Pos
+}
+
+func (e *Error) Type() ObjectType { return ERROR_OBJ }
+func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+
+// Function : a function value, which carries the environment it was
+// defined in, so that the body can refer to names bound in that scope
+// even after it has been returned, i.e. a closure
+type Function struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("fn(")
+	out.WriteString(strings.Join(params, ", "))
+	out.WriteString(") {\n")
+	out.WriteString(f.Body.String())
+	out.WriteString("\n}")
+
+	return out.String()
+}
+
+// Array : an ordered list of values, which may be of different types
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string {
+	return inspect(a, map[Object]bool{})
+}
+
+// HashPair : the original key object is kept alongside
+// the value, so that the hash can be inspected
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash : maps hashable keys to values of any type
+type Hash struct {
+	Pairs map[HashKey]HashPair
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+
+// Inspect : pairs are sorted, so that the same hash
+// always prints the same way
+func (h *Hash) Inspect() string {
+	return inspect(h, map[Object]bool{})
+}
+
+// inspect : obj as Inspect would print it. An array or hash can contain
+// itself once it has been assigned into, e.g. a[0] = a, so one which is
+// already being printed further up is printed as [...] or {...} instead
+func inspect(obj Object, printing map[Object]bool) string {
+	var out bytes.Buffer
+
+	switch obj := obj.(type) {
+	case *Array:
+		if printing[obj] {
+			return "[...]"
+		}
+		printing[obj] = true
+		defer delete(printing, obj)
+
+		elements := []string{}
+		for _, e := range obj.Elements {
+			elements = append(elements, inspect(e, printing))
+		}
+
+		out.WriteString("[")
+		out.WriteString(strings.Join(elements, ", "))
+		out.WriteString("]")
+
+	case *Hash:
+		if printing[obj] {
+			return "{...}"
+		}
+		printing[obj] = true
+		defer delete(printing, obj)
+
+		pairs := []string{}
+		for _, pair := range obj.Pairs {
+			pairs = append(pairs, fmt.Sprintf("%s: %s", inspect(pair.Key, printing), inspect(pair.Value, printing)))
+		}
+		sort.Strings(pairs)
+
+		out.WriteString("{")
+		out.WriteString(strings.Join(pairs, ", "))
+		out.WriteString("}")
+
+	default:
+		return obj.Inspect()
+	}
+
+	return out.String()
+}
@@ -0,0 +1,52 @@
+package object
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestStringHashKey(t *testing.T) {
+	hello1 := &String{Value: "Hello World"}
+	hello2 := &String{Value: "Hello World"}
+	diff1 := &String{Value: "My name is johnny"}
+	diff2 := &String{Value: "My name is johnny"}
+
+	if hello1.HashKey() != hello2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if diff1.HashKey() != diff2.HashKey() {
+		t.Errorf("strings with same content have different hash keys")
+	}
+
+	if hello1.HashKey() == diff1.HashKey() {
+		t.Errorf("strings with different content have same hash keys")
+	}
+}
+
+func TestHashKeyTypes(t *testing.T) {
+	one := &Integer{Value: 1}
+	yes := &Boolean{Value: true}
+
+	if one.HashKey() == yes.HashKey() {
+		t.Errorf("integer 1 and true have the same hash key")
+	}
+
+	if one.HashKey() != (&Integer{Value: 1}).HashKey() {
+		t.Errorf("integers with same value have different hash keys")
+	}
+}
+
+func TestBigIntHashKey(t *testing.T) {
+	a, _ := new(big.Int).SetString("99999999999999999999", 10)
+	b, _ := new(big.Int).SetString("99999999999999999999", 10)
+	c, _ := new(big.Int).SetString("-99999999999999999999", 10)
+
+	if (&BigInt{Value: a}).HashKey() != (&BigInt{Value: b}).HashKey() {
+		t.Errorf("big integers with same value have different hash keys")
+	}
+
+	if (&BigInt{Value: a}).HashKey() == (&BigInt{Value: c}).HashKey() {
+		t.Errorf("big integers with different values have the same hash key")
+	}
+}
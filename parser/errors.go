@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+
+	"go-interpreter/token"
+)
+
+// Severity : how serious a parse error is, only errors
+// stop a program from being run
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// Error : a single problem found while parsing. Expected holds the
+// token types that would have been accepted, if there is such a set,
+// and Actual is the token that was found instead
+type Error struct {
+	Pos      token.Pos
+	Msg      string
+	Expected []token.TokenType
+	Actual   token.Token
+	Severity Severity
+}
+
+// Error : formats the error as position: message
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// ErrorList : a list of parse errors, which is itself an error
+type ErrorList []*Error
+
+// Add : append an error with the given position and message
+func (l *ErrorList) Add(pos token.Pos, msg string) {
+	*l = append(*l, &Error{Pos: pos, Msg: msg})
+}
+
+// sort.Interface, errors are ordered by filename, then line, then
+// column, errors at the same position keep the order they were found in
+func (l ErrorList) Len() int      { return len(l) }
+func (l ErrorList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l ErrorList) Less(i, j int) bool {
+	e, f := l[i].Pos, l[j].Pos
+	if e.Filename != f.Filename {
+		return e.Filename < f.Filename
+	}
+	if e.Line != f.Line {
+		return e.Line < f.Line
+	}
+	return e.Column < f.Column
+}
+
+// Sort : sort the list in place by position
+func (l ErrorList) Sort() {
+	sort.Stable(l)
+}
+
+// RemoveMultiples : sort the list, and remove all but the first of
+// any errors which have the same position and message
+func (l *ErrorList) RemoveMultiples() {
+	l.Sort()
+
+	i := 0
+	for _, e := range *l {
+		if !l.seen(i, e) {
+			(*l)[i] = e
+			i++
+		}
+	}
+	*l = (*l)[:i]
+}
+
+// seen : whether e is a duplicate of one of the first n errors, since
+// the list is sorted, only the errors at the same position are checked
+func (l ErrorList) seen(n int, e *Error) bool {
+	for j := n - 1; j >= 0 && l[j].Pos == e.Pos; j-- {
+		if l[j].Msg == e.Msg {
+			return true
+		}
+	}
+	return false
+}
+
+// Error : implements the error interface, describing
+// the first error and how many others there are
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err : returns nil if the list is empty, so that an empty
+// list is not mistaken for an error, otherwise the list itself
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"go-interpreter/lexer"
+	"go-interpreter/token"
+	"testing"
+)
+
+func TestErrorListSortAndRemoveMultiples(t *testing.T) {
+	pos := func(line, column int) token.Pos {
+		return token.Pos{Line: line, Column: column}
+	}
+
+	var list ErrorList
+	list.Add(pos(3, 1), "c")
+	list.Add(pos(1, 5), "b")
+	list.Add(pos(1, 2), "a")
+	list.Add(pos(1, 5), "b")
+	list.Add(pos(1, 5), "d")
+	list.Add(pos(1, 5), "b")
+
+	list.RemoveMultiples()
+
+	expected := []string{"1:2: a", "1:5: b", "1:5: d", "3:1: c"}
+	if len(list) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d, got=%d (%v)", len(expected), len(list), list)
+	}
+
+	for i, msg := range expected {
+		if list[i].Error() != msg {
+			t.Errorf("list[%d] wrong. expected=%q, got=%q", i, msg, list[i].Error())
+		}
+	}
+
+	if list.Error() != "1:2: a (and 3 more errors)" {
+		t.Errorf("list.Error() wrong. got=%q", list.Error())
+	}
+}
+
+func TestErrorListErr(t *testing.T) {
+	var list ErrorList
+	if list.Err() != nil {
+		t.Errorf("empty list should have nil Err(). got=%v", list.Err())
+	}
+
+	list.Add(token.Pos{Line: 1, Column: 1}, "oops")
+	if list.Err() == nil {
+		t.Fatalf("non-empty list should have non-nil Err()")
+	}
+
+	if list.Err().Error() != "1:1: oops" {
+		t.Errorf("Err().Error() wrong. got=%q", list.Err().Error())
+	}
+}
+
+func TestStructuredErrors(t *testing.T) {
+	l := lexer.New("let = 5;")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	err := errors[0]
+	if err.Severity != SeverityError {
+		t.Errorf("wrong severity. expected=%s, got=%s", SeverityError, err.Severity)
+	}
+
+	if len(err.Expected) != 1 || err.Expected[0] != token.IDENT {
+		t.Errorf("wrong expected tokens. got=%v", err.Expected)
+	}
+
+	if err.Actual.Type != token.ASSIGN {
+		t.Errorf("wrong actual token. expected=%q, got=%q", token.ASSIGN, err.Actual.Type)
+	}
+
+	if err.Pos.Line != 1 || err.Pos.Column != 5 {
+		t.Errorf("wrong position. got=%s", err.Pos)
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedErrors []string
+		expectedStmts  int
+	}{
+		{
+			"let = 1;\nlet y 2;\nlet z = ;\nlet ok = 5;",
+			[]string{
+				"1:5: expected next token to be IDENT, got = instead",
+				"2:7: expected next token to be =, got INT instead",
+				"3:9: no prefix parse function for ; found",
+			},
+			1,
+		},
+		{
+			"let x = (1 + 2;\nlet y = fn(1) { y };\nx + y;",
+			[]string{
+				"1:15: expected next token to be ), got ; instead",
+				"2:12: expected next token to be IDENT, got INT instead",
+			},
+			1,
+		},
+		{
+			"let f = fn(x) {\n  let = 1;\n  let y = ;\n  x\n};\nf(1);",
+			[]string{
+				"2:7: expected next token to be IDENT, got = instead",
+				"3:11: no prefix parse function for ; found",
+			},
+			2,
+		},
+		{
+			"if (x) { let y = }\nlet z = 1;",
+			[]string{
+				"1:18: no prefix parse function for } found",
+			},
+			2,
+		},
+		{
+			`let h = {"a" 1, "b": 2}; let ok = h;`,
+			[]string{
+				`1:14: expected next token to be :, got INT instead`,
+			},
+			1,
+		},
+		{
+			// each error is found at the let of the next statement
+			"let x = 1 +\nlet y = 2 *\nlet z = 3 /",
+			[]string{
+				"1:12: no prefix parse function for LET found",
+				"2:12: no prefix parse function for LET found",
+				"3:12: no prefix parse function for EOF found",
+			},
+			0,
+		},
+		{
+			"let x = 1 +\nreturn x;",
+			[]string{
+				"1:12: no prefix parse function for RETURN found",
+			},
+			1,
+		},
+		{
+			"let f = fn() {\n  let a = -\n  let b = 1;\n  b\n};",
+			[]string{
+				"2:12: no prefix parse function for LET found",
+			},
+			1,
+		},
+		{
+			// the failed statement started at the let, so it is not parsed again
+			"let\nlet y = 1;",
+			[]string{
+				"2:1: expected next token to be IDENT, got LET instead",
+			},
+			1,
+		},
+		{
+			"if (x { 1 } else { 2 }; let ok = 1;",
+			[]string{
+				"1:7: expected next token to be ), got { instead",
+			},
+			1,
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Errorf("wrong number of errors for %q. expected=%d, got=%d (%v)",
+				tt.input, len(tt.expectedErrors), len(errors), errors)
+			continue
+		}
+
+		for i, msg := range tt.expectedErrors {
+			if errors[i].Error() != msg {
+				t.Errorf("errors[%d] wrong for %q. expected=%q, got=%q", i, tt.input, msg, errors[i].Error())
+			}
+		}
+
+		if len(program.Statements) != tt.expectedStmts {
+			t.Errorf("wrong number of statements for %q. expected=%d, got=%d",
+				tt.input, tt.expectedStmts, len(program.Statements))
+		}
+	}
+}
+
+func TestErrorLimit(t *testing.T) {
+	input := ""
+	for i := 0; i < maxErrors+5; i++ {
+		input += "let = 1;\n"
+	}
+
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) != maxErrors {
+		t.Errorf("wrong number of errors. expected=%d, got=%d", maxErrors, len(p.Errors()))
+	}
+}
+
+func TestErrorLimitInBlock(t *testing.T) {
+	input := "fn() {"
+	for i := 0; i < maxErrors+1; i++ {
+		input += " let a = ;"
+	}
+	input += " let l ; if ( }"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != maxErrors {
+		t.Errorf("wrong number of errors. expected=%d, got=%d", maxErrors, len(p.Errors()))
+	}
+
+	// the statements which failed to parse are not kept, so
+	// printing the program does not dereference a nil statement
+	_ = program.String()
+}
@@ -2,7 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
+	"unicode/utf8"
 
 	"go-interpreter/ast"
 	"go-interpreter/lexer"
@@ -12,28 +14,82 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	LOGICALOR   // ||
+	LOGICALAND  // &&
 	EQUALS      // ==
 	LESSGREATER // > or <
+	BITOR       // |
+	BITXOR      // ^
+	BITAND      // &
+	SHIFT       // << or >>
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
+	POWER       // X ** Y, binds tighter than prefix, so -2 ** 2 is -(2 ** 2)
 	CALL        // myFunction(X)
 	INDEX       // array[index]
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:     EQUALS,
-	token.NEQ:    EQUALS,
-	token.LT:     LESSGREATER,
-	token.GT:     LESSGREATER,
-	token.PLUS:   SUM,
-	token.MINUS:  SUM,
-	token.SLASH:  PRODUCT,
-	token.TIMES:  PRODUCT,
-	token.LPAREN: CALL,
-	token.LBRACE: INDEX,
+	token.OR:       LOGICALOR,
+	token.AND:      LOGICALAND,
+	token.EQ:       EQUALS,
+	token.NEQ:      EQUALS,
+	token.LT:       LESSGREATER,
+	token.GT:       LESSGREATER,
+	token.LTE:      LESSGREATER,
+	token.GTE:      LESSGREATER,
+	token.BITOR:    BITOR,
+	token.BITXOR:   BITXOR,
+	token.BITAND:   BITAND,
+	token.SHL:      SHIFT,
+	token.SHR:      SHIFT,
+	token.PLUS:     SUM,
+	token.MINUS:    SUM,
+	token.SLASH:    PRODUCT,
+	token.TIMES:    PRODUCT,
+	token.MODULO:   PRODUCT,
+	token.POWER:    POWER,
+	token.LPAREN:   CALL,
+	token.LBRACKET: INDEX,
 }
 
+// rightAssociative : the operators which group from the right,
+// e.g. 2 ** 3 ** 2 is 2 ** (3 ** 2)
+var rightAssociative = map[token.TokenType]bool{
+	token.POWER: true,
+}
+
+// assignOperators : the operators which assign to the expression on
+// their left, as the only operator of an expression statement
+var assignOperators = map[token.TokenType]bool{
+	token.ASSIGN:        true,
+	token.PLUS_ASSIGN:   true,
+	token.MINUS_ASSIGN:  true,
+	token.TIMES_ASSIGN:  true,
+	token.SLASH_ASSIGN:  true,
+	token.MODULO_ASSIGN: true,
+}
+
+// RightAssociative : whether the infix operator t groups from the right
+func RightAssociative(t token.TokenType) bool {
+	return rightAssociative[t]
+}
+
+// Precedence : how tightly the infix operator t binds to its operands,
+// LOWEST if t is not an infix operator. Used by the formatter to decide
+// where parentheses are needed
+func Precedence(t token.TokenType) int {
+	if p, ok := precedences[t]; ok {
+		return p
+	}
+	return LOWEST
+}
+
+// maxErrors : parsing stops once this many errors have been found,
+// as any further errors are likely to be caused by the earlier ones
+const maxErrors = 10
+
 type (
 	prefixParseFn func() ast.Expression
 	infixParseFn  func(ast.Expression) ast.Expression
@@ -41,10 +97,20 @@ type (
 
 type Parser struct {
 	l      *lexer.Lexer
-	errors []string
+	errors ErrorList
 
 	curToken  token.Token
 	peekToken token.Token
+	prevEnd   token.Pos // the end of the token before curToken
+
+	// depth is the number of braces opened up to and including curToken
+	// that have not been closed yet, used to recover from errors
+	depth int
+	// panicking is set once an error is found in a statement, further
+	// errors are not reported until we have skipped to the next statement
+	panicking bool
+
+	comments []token.Token // the comments lexed so far, in order
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
@@ -53,7 +119,7 @@ type Parser struct {
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
-		errors: []string{},
+		errors: ErrorList{},
 	}
 
 	// Read two tokens, so curToken and peekToken are both set
@@ -63,8 +129,18 @@ func New(l *lexer.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.BITNOT, p.parsePrefixExpression)
+	p.registerPrefix(token.TRUE, p.parseBoolean)
+	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
+	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -75,26 +151,72 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NEQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LTE, p.parseInfixExpression)
+	p.registerInfix(token.GTE, p.parseInfixExpression)
+	p.registerInfix(token.MODULO, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.BITAND, p.parseInfixExpression)
+	p.registerInfix(token.BITOR, p.parseInfixExpression)
+	p.registerInfix(token.BITXOR, p.parseInfixExpression)
+	p.registerInfix(token.SHL, p.parseInfixExpression)
+	p.registerInfix(token.SHR, p.parseInfixExpression)
+	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	return p
 }
 
-func (p *Parser) Errors() []string {
-	return p.errors
+// Errors : the errors found while parsing, sorted by
+// position and with duplicates removed
+func (p *Parser) Errors() ErrorList {
+	errors := make(ErrorList, len(p.errors))
+	copy(errors, p.errors)
+	errors.RemoveMultiples()
+	return errors
+}
+
+// Comments : the comments in the input, in the order they were found.
+// Only returned if the lexer was created with the KeepComments mode
+func (p *Parser) Comments() []token.Token {
+	return p.comments
 }
 
 func (p *Parser) nextToken() {
+	p.prevEnd = p.curToken.End
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
+
+	// comments are not part of the AST, but are kept for the
+	// formatter if the lexer was created to return them
+	for p.peekToken.Type == token.COMMENT {
+		p.comments = append(p.comments, p.peekToken)
+		p.peekToken = p.l.NextToken()
+	}
+
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.depth++
+	case token.RBRACE:
+		if p.depth > 0 {
+			p.depth--
+		}
+	}
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
-	for p.curToken.Type != token.EOF {
+	for p.curToken.Type != token.EOF && len(p.errors) < maxErrors {
+		start := p.curToken.Pos
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if p.panicking {
+			if p.synchronize(0, start) {
+				continue
+			}
+		} else if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
 		p.nextToken()
@@ -103,15 +225,60 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// synchronize : after an error, skip the rest of the statement that failed
+// to parse, so that the next call to nextToken moves to the start of the
+// following statement (or the } of the enclosing block). depth is the brace
+// depth of the statement list being parsed, braces opened within the failed
+// statement are skipped over as a whole. start is the position the failed
+// statement started at. Returns true if the error was found at the let or
+// return starting the next statement, which is then the current token, and
+// must be parsed from rather than moved past
+func (p *Parser) synchronize(depth int, start token.Pos) bool {
+	p.panicking = false
+
+	for !p.curTokenIs(token.EOF) {
+		// the error was at the } closing the statement list
+		if p.depth < depth {
+			return false
+		}
+
+		if p.depth == depth {
+			if p.curTokenIs(token.SEMICOLON) {
+				return false
+			}
+			if (p.curTokenIs(token.LET) || p.curTokenIs(token.RETURN)) && p.curToken.Pos.Offset != start.Offset {
+				return true
+			}
+			switch p.peekToken.Type {
+			case token.LET, token.RETURN, token.RBRACE:
+				return false
+			}
+		}
+
+		p.nextToken()
+	}
+
+	return false
+}
+
+// parseStatement : a statement which fails to parse is returned as a
+// nil Statement, rather than one holding a nil *ast.LetStatement etc
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.LET:
-		return p.parseLetStatement()
+		if stmt := p.parseLetStatement(); stmt != nil {
+			return stmt
+		}
 	case token.RETURN:
-		return p.parseReturnStatement()
+		if stmt := p.parseReturnStatement(); stmt != nil {
+			return stmt
+		}
 	default:
-		return p.parseExpressionStatement()
+		if stmt := p.parseExpressionStatement(); stmt != nil {
+			return stmt
+		}
 	}
+	return nil
 }
 
 func (p *Parser) parseLetStatement() *ast.LetStatement {
@@ -162,6 +329,11 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	}
 	stmt.Expression = p.parseExpression(LOWEST)
 
+	if assignOperators[p.peekToken.Type] {
+		p.nextToken()
+		stmt.Expression = p.parseAssignExpression(stmt.Expression)
+	}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -169,6 +341,41 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	return stmt
 }
 
+// parseAssignExpression : assignment is only parsed at the start of an
+// expression statement, not as an infix operator, so that e.g. the =
+// in if (x = 1) is still reported as an error rather than assigning
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	exp := &ast.AssignExpression{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: p.curToken.Literal,
+	}
+
+	// an error earlier in the statement may have left the target
+	// incomplete, e.g. the - of - += 1 with no right side
+	if p.panicking {
+		return nil
+	}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+	case nil:
+		return nil
+	default:
+		msg := fmt.Sprintf("cannot assign to %s", target.String())
+		p.addError(p.curToken, nil, msg)
+		return nil
+	}
+
+	p.nextToken()
+	exp.Value = p.parseExpression(LOWEST)
+	if exp.Value == nil {
+		return nil
+	}
+
+	return exp
+}
+
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
 	if prefix == nil {
@@ -201,10 +408,38 @@ func (p *Parser) parseIdentifier() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
-	if err != nil {
+	if leadingZero(p.curToken.Literal) {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken, nil, msg)
+		return nil
+	}
+
+	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if err == nil {
+		lit.Value = value
+		return lit
+	}
+
+	// a valid literal which is out of range is kept as a big.Int
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		if n, ok := new(big.Int).SetString(p.curToken.Literal, 0); ok {
+			lit.Big = n
+			return lit
+		}
+	}
+
+	msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
+	p.addError(p.curToken, nil, msg)
+	return nil
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil || leadingZero(p.curToken.Literal) {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.addError(p.curToken, nil, msg)
 		return nil
 	}
 
@@ -212,6 +447,26 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+// leadingZero : whether a decimal number literal starts with a 0 which
+// is followed by more digits, e.g. 010 or 01.5. strconv would read the
+// 0 of an integer as an octal prefix, but only 0x, 0o and 0b are prefixes
+// here, so these are rejected rather than being 8 as in C, and the same
+// goes for floats so the rule is the same for all numbers
+func leadingZero(literal string) bool {
+	return len(literal) > 1 && literal[0] == '0' && ('0' <= literal[1] && literal[1] <= '9' || literal[1] == '_')
+}
+
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+func (p *Parser) parseBoolean() ast.Expression {
+	return &ast.Boolean{
+		Token: p.curToken,
+		Value: p.curTokenIs(token.TRUE),
+	}
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Token:    p.curToken,
@@ -232,13 +487,141 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 		Left:     left,
 	}
 
+	// the right side of a right associative operator takes in any
+	// operators of the same precedence, rather than stopping at them
 	precedence := p.curPrecedence()
+	if rightAssociative[p.curToken.Type] {
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+// parseGroupedExpression : parses the expression inside of parentheses
+// starting from the lowest precedence, so that it is bound together
+// regardless of the operators surrounding the group
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	p.nextToken()
+
+	exp := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	return exp
+}
+
+func (p *Parser) parseIfExpression() ast.Expression {
+	expression := &ast.IfExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Consequence = p.parseBlockStatement()
+
+	if !p.peekTokenIs(token.ELSE) {
+		return expression
+	}
+
+	p.nextToken()
+
+	// else if: parse the nested if expression, and wrap it
+	// in a block so that it can be used as the alternative
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		nestedToken := p.curToken
+		nested := p.parseIfExpression()
+		if nested == nil {
+			return nil
+		}
+		expression.Alternative = &ast.BlockStatement{
+			Token: nestedToken,
+			Statements: []ast.Statement{
+				&ast.ExpressionStatement{Token: nestedToken, Expression: nested},
+			},
+		}
+		return expression
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Alternative = p.parseBlockStatement()
+
+	return expression
+}
+
+// parseBlockStatement : parses statements until we reach the closing
+// brace of the block, or the end of the input
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	block := &ast.BlockStatement{Token: p.curToken}
+	block.Statements = []ast.Statement{}
+	depth := p.depth
+
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) && len(p.errors) < maxErrors {
+		start := p.curToken.Pos
+		stmt := p.parseStatement()
+		if p.panicking {
+			atStatement := p.synchronize(depth, start)
+			// the block was closed by the token the error was found at
+			if p.depth < depth {
+				break
+			}
+			if atStatement {
+				continue
+			}
+		} else if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+		}
+		p.nextToken()
+	}
+
+	if p.curTokenIs(token.RBRACE) {
+		block.Rbrace = p.curToken.Pos
+	}
+
+	return block
+}
+
+func (p *Parser) parseFunctionLiteral() ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	lit.Parameters = p.parseFunctionParameters()
+	if lit.Parameters == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	lit.Body = p.parseBlockStatement()
+
+	return lit
+}
+
 func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	identifiers := []*ast.Identifier{}
 
@@ -247,7 +630,10 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 		return identifiers
 	}
 
-	p.nextToken()
+	// every parameter must be an identifier, e.g. fn(1) is not allowed
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
 
 	ident := &ast.Identifier{
 		Token: p.curToken,
@@ -257,7 +643,9 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
-		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
 		ident := &ast.Identifier{
 			Token: p.curToken,
 			Value: p.curToken.Literal,
@@ -272,6 +660,20 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	return identifiers
 }
 
+// parseCallExpression : the ( of a call is parsed as an infix operator,
+// with the function being called as its left side, e.g. add(1, 2)
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	exp := &ast.CallExpression{Token: p.curToken, Function: function}
+
+	exp.Arguments = p.parseCallArguments()
+	if exp.Arguments == nil {
+		return nil
+	}
+	exp.Rparen = p.curToken.Pos
+
+	return exp
+}
+
 func (p *Parser) parseCallArguments() []ast.Expression {
 	args := []ast.Expression{}
 
@@ -296,6 +698,66 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 	return args
 }
 
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	array := &ast.ArrayLiteral{Token: p.curToken}
+
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if array.Elements == nil {
+		return nil
+	}
+	array.Rbracket = p.curToken.Pos
+
+	return array
+}
+
+// parseIndexExpression : the [ of an index is parsed as an infix operator,
+// with the array being indexed as its left side, e.g. myArray[1]
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+	exp.Rbracket = p.curToken.Pos
+
+	return exp
+}
+
+// parseHashLiteral : a { in expression position begins a hash literal,
+// blocks are only parsed where a statement list is expected, e.g. the
+// body of a function or the branches of an if expression
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.curToken, Pairs: []ast.HashPair{}}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	hash.Rbrace = p.curToken.Pos
+
+	return hash
+}
+
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	list := []ast.Expression{}
 
@@ -356,7 +818,7 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, []token.TokenType{t}, msg)
 }
 
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
@@ -369,5 +831,45 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	// the lexer returns a byte which is not valid UTF-8 on its own
+	if t == token.ILLEGAL && !utf8.ValidString(p.curToken.Literal) {
+		msg = fmt.Sprintf("invalid UTF-8 encoding %q", p.curToken.Literal)
+	}
+
+	// an expression cut short by the start of the next statement, e.g. the
+	// 1 + of let x = 1 + on the line before a let, is reported where it
+	// stops rather than at the next statement
+	if (t == token.LET || t == token.RETURN) && p.prevEnd.IsValid() {
+		p.addErrorAt(p.prevEnd, p.curToken, nil, msg)
+		return
+	}
+	p.addError(p.curToken, nil, msg)
+}
+
+// addError : record an error at the position of the actual token found,
+// expected is the set of token types that would have been accepted. Only
+// the first error of each statement is recorded, and none after the
+// first maxErrors, though the statement is still marked as failed
+func (p *Parser) addError(actual token.Token, expected []token.TokenType, msg string) {
+	p.addErrorAt(actual.Pos, actual, expected, msg)
+}
+
+// addErrorAt : record an error as addError does, but at pos
+// rather than the position of the actual token
+func (p *Parser) addErrorAt(pos token.Pos, actual token.Token, expected []token.TokenType, msg string) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+	if len(p.errors) >= maxErrors {
+		return
+	}
+
+	p.errors = append(p.errors, &Error{
+		Pos:      pos,
+		Msg:      msg,
+		Expected: expected,
+		Actual:   actual,
+		Severity: SeverityError,
+	})
 }
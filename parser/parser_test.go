@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"go-interpreter/ast"
 	"go-interpreter/lexer"
+	"go-interpreter/token"
+	"strings"
 	"testing"
 )
 
@@ -186,6 +188,143 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestNumberLiterals(t *testing.T) {
+	integers := []struct {
+		input    string
+		expected int64
+	}{
+		{"1_000_000", 1000000},
+		{"0xff", 255},
+		{"0o17", 15},
+		{"0b101", 5},
+		{"0", 0},
+		{"0XFF", 255},
+		{"0B1_0", 2},
+	}
+
+	for _, tt := range integers {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("%q is not an integer literal. got=%T", tt.input, stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("%q - wrong value. expected=%d, got=%d", tt.input, tt.expected, literal.Value)
+		}
+		// the literal is kept as written, e.g. for the formatter
+		if literal.String() != tt.input {
+			t.Errorf("%q - wrong String(). got=%q", tt.input, literal.String())
+		}
+	}
+
+	floats := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5", 1.5},
+		{"1_000.25", 1000.25},
+		{"1e3", 1000},
+		{"2.5e-3", 0.0025},
+		{"0.5", 0.5},
+		{"0e3", 0},
+		{"0.05", 0.05},
+	}
+
+	for _, tt := range floats {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("%q is not a float literal. got=%T", tt.input, stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("%q - wrong value. expected=%g, got=%g", tt.input, tt.expected, literal.Value)
+		}
+		if literal.String() != tt.input {
+			t.Errorf("%q - wrong String(). got=%q", tt.input, literal.String())
+		}
+	}
+}
+
+func TestBigIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807", ""},
+		{"9223372036854775808", "9223372036854775808"},
+		{"99_999_999_999_999_999_999", "99999999999999999999"},
+		{"0xffff_ffff_ffff_ffff_ffff", "1208925819614629174706175"},
+		{"0b1" + strings.Repeat("0", 64), "18446744073709551616"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("%q is not an integer literal. got=%T", tt.input, stmt.Expression)
+		}
+
+		if tt.expected == "" {
+			if literal.Big != nil {
+				t.Errorf("%q - expected an int64 value, got big %s", tt.input, literal.Big)
+			}
+			continue
+		}
+		if literal.Big == nil || literal.Big.String() != tt.expected {
+			t.Errorf("%q - wrong big value. expected=%s, got=%v", tt.input, tt.expected, literal.Big)
+		}
+	}
+}
+
+func TestInvalidNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0b102", `1:1: could not parse "0b102" as integer`},
+		{"1__0", `1:1: could not parse "1__0" as integer`},
+		{"let x = 12abc;", `1:9: could not parse "12abc" as integer`},
+		{"3e", `1:1: could not parse "3e" as float`},
+		{"1_.5", `1:1: could not parse "1_.5" as float`},
+		{"1e400", `1:1: could not parse "1e400" as float`},
+		// a leading 0 is not an octal prefix
+		{"010", `1:1: could not parse "010" as integer`},
+		{"08", `1:1: could not parse "08" as integer`},
+		{"0_10", `1:1: could not parse "0_10" as integer`},
+		{"00", `1:1: could not parse "00" as integer`},
+		{"099999999999999999999", `1:1: could not parse "099999999999999999999" as integer`},
+		{"00.5", `1:1: could not parse "00.5" as float`},
+		{"01.5", `1:1: could not parse "01.5" as float`},
+		{"0_1.5", `1:1: could not parse "0_1.5" as float`},
+		{"01e3", `1:1: could not parse "01e3" as float`},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
 func testIntegerLiteral(t *testing.T, il ast.Expression, value int64) bool {
 	integ, ok := il.(*ast.IntegerLiteral)
 	if !ok {
@@ -213,6 +352,7 @@ func TestParsingPrefixExpressions(t *testing.T) {
 	}{
 		{"!5;", "!", 5},
 		{"-15;", "-", 15},
+		{"~15;", "~", 15},
 	}
 	for _, tt := range prefixTests {
 		l := lexer.New(tt.input)
@@ -254,6 +394,17 @@ func TestParsingInfixExpressions(t *testing.T) {
 	}{
 		{"5 + 5;", 5, "+", 5},
 		{"5 * 5;", 5, "*", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"5 ** 5;", 5, "**", 5},
+		{"5 && 5;", 5, "&&", 5},
+		{"5 || 5;", 5, "||", 5},
+		{"5 & 5;", 5, "&", 5},
+		{"5 | 5;", 5, "|", 5},
+		{"5 ^ 5;", 5, "^", 5},
+		{"5 << 5;", 5, "<<", 5},
+		{"5 >> 5;", 5, ">>", 5},
 	}
 
 	for _, tt := range infixTests {
@@ -289,3 +440,838 @@ func TestParsingInfixExpressions(t *testing.T) {
 		}
 	}
 }
+
+func TestBooleanExpression(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedBoolean bool
+	}{
+		{"true;", true},
+		{"false;", false},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not an expression statement. got=%T", program.Statements[0])
+		}
+
+		boolean, ok := stmt.Expression.(*ast.Boolean)
+		if !ok {
+			t.Fatalf("exp not *ast.Boolean. got=%T", stmt.Expression)
+		}
+
+		if boolean.Value != tt.expectedBoolean {
+			t.Errorf("boolean.Value not %t. got=%t", tt.expectedBoolean, boolean.Value)
+		}
+	}
+}
+
+func TestOperatorPrecedenceParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"-a * b", "((-a)*b)"},
+		{"!-a", "(!(-a))"},
+		{"a + b + c", "((a+b)+c)"},
+		{"a + b - c", "((a+b)-c)"},
+		{"a * b * c", "((a*b)*c)"},
+		{"a * b / c", "((a*b)/c)"},
+		{"a + b / c", "(a+(b/c))"},
+		{"a + b * c + d / e - f", "(((a+(b*c))+(d/e))-f)"},
+		{"5 > 4 == 3 < 4", "((5>4)==(3<4))"},
+		{"5 < 4 != 3 > 4", "((5<4)!=(3>4))"},
+		{"3 + 4 * 5 == 3 * 1 + 4 * 5", "((3+(4*5))==((3*1)+(4*5)))"},
+		{"true", "true"},
+		{"false", "false"},
+		{"!true", "(!true)"},
+		{"!false", "(!false)"},
+		{"3 > 5 == false", "((3>5)==false)"},
+		{"3 < 5 == true", "((3<5)==true)"},
+		{"1 < 2 == true", "((1<2)==true)"},
+		{"1 + (2 + 3) + 4", "((1+(2+3))+4)"},
+		{"(5 + 5) * 2", "((5+5)*2)"},
+		{"(1 + 2) * 3", "((1+2)*3)"},
+		{"2 / (5 + 5)", "(2/(5+5))"},
+		{"-(5 + 5)", "(-(5+5))"},
+		{"!(true == true)", "(!(true==true))"},
+		{"((a))", "a"},
+		{"a * (b - (c + d))", "(a*(b-(c+d)))"},
+		{"a + add(b * c) + d", "((a+add((b*c)))+d)"},
+		{"add(a, b, 1, 2 * 3, 4 + 5, add(6, 7 * 8))", "add(a, b, 1, (2*3), (4+5), add(6, (7*8)))"},
+		{"add(a + b + c * d / f + g)", "add((((a+b)+((c*d)/f))+g))"},
+		{"fn(x) { x }(5)", "fn(x) x(5)"},
+		{"a * [1, 2, 3, 4][b * c] * d", "((a*([1, 2, 3, 4][(b*c)]))*d)"},
+		{"add(a * b[2], b[1], 2 * [1, 2][1])", "add((a*(b[2])), (b[1]), (2*([1, 2][1])))"},
+		{"a[0][1]", "((a[0])[1])"},
+		{"-a[0]", "(-(a[0]))"},
+		{"a <= b == c >= d", "((a<=b)==(c>=d))"},
+		{"a + b % c", "(a+(b%c))"},
+		{"a % b * c", "((a%b)*c)"},
+		{"a ** b ** c", "(a**(b**c))"},
+		{"a * b ** c", "(a*(b**c))"},
+		{"-a ** b", "(-(a**b))"},
+		{"a ** -b", "(a**(-b))"},
+		{"(-a) ** b", "((-a)**b)"},
+		{"a ** b[0]", "(a**(b[0]))"},
+		{"a || b && c", "(a||(b&&c))"},
+		{"a && b || c && d", "((a&&b)||(c&&d))"},
+		{"a || b || c", "((a||b)||c)"},
+		{"a == b && c < d", "((a==b)&&(c<d))"},
+		{"!a && b", "((!a)&&b)"},
+		{"a | b ^ c & d", "(a|(b^(c&d)))"},
+		{"a & b == c", "((a&b)==c)"},
+		{"a << b + c", "(a<<(b+c))"},
+		{"a & b << c", "(a&(b<<c))"},
+		{"a >> b >> c", "((a>>b)>>c)"},
+		{"~a & b", "((~a)&b)"},
+		{"~a ** b", "(~(a**b))"},
+		{"a < b | c", "(a<(b|c))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+}
+
+func TestGroupedExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(1 + 2", "1:7: expected next token to be ), got EOF instead"},
+		{"(1 + 2;", "1:7: expected next token to be ), got ; instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func testIdentifier(t *testing.T, exp ast.Expression, value string) bool {
+	ident, ok := exp.(*ast.Identifier)
+	if !ok {
+		t.Errorf("exp not *ast.Identifier. got=%T", exp)
+		return false
+	}
+
+	if ident.Value != value {
+		t.Errorf("ident.Value not %s. got=%s", value, ident.Value)
+		return false
+	}
+
+	if ident.TokenLiteral() != value {
+		t.Errorf("ident.TokenLiteral not %s. got=%s", value, ident.TokenLiteral())
+		return false
+	}
+
+	return true
+}
+
+func testInfixIdentifiers(t *testing.T, exp ast.Expression, left, operator, right string) bool {
+	opExp, ok := exp.(*ast.InfixExpression)
+	if !ok {
+		t.Errorf("exp is not ast.InfixExpression. got=%T(%s)", exp, exp)
+		return false
+	}
+
+	if !testIdentifier(t, opExp.Left, left) {
+		return false
+	}
+
+	if opExp.Operator != operator {
+		t.Errorf("exp.Operator is not '%s'. got=%q", operator, opExp.Operator)
+		return false
+	}
+
+	return testIdentifier(t, opExp.Right, right)
+}
+
+func TestIfExpression(t *testing.T) {
+	input := `if (x < y) { x }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not an expression statement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if !testInfixIdentifiers(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(exp.Consequence.Statements) != 1 {
+		t.Fatalf("consequence is not 1 statement. got=%d", len(exp.Consequence.Statements))
+	}
+
+	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T", exp.Consequence.Statements[0])
+	}
+
+	if !testIdentifier(t, consequence.Expression, "x") {
+		return
+	}
+
+	if exp.Alternative != nil {
+		t.Errorf("exp.Alternative was not nil. got=%+v", exp.Alternative)
+	}
+}
+
+func TestIfElseExpression(t *testing.T) {
+	input := `if (x < y) { x } else { y }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not an expression statement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if !testInfixIdentifiers(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	if exp.Alternative == nil {
+		t.Fatalf("exp.Alternative is nil")
+	}
+
+	if len(exp.Alternative.Statements) != 1 {
+		t.Fatalf("alternative is not 1 statement. got=%d", len(exp.Alternative.Statements))
+	}
+
+	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T", exp.Alternative.Statements[0])
+	}
+
+	if !testIdentifier(t, alternative.Expression, "y") {
+		return
+	}
+}
+
+func TestIfElseIfExpression(t *testing.T) {
+	input := `if (x < y) { x } else if (x > y) { y } else { z }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if exp.Alternative == nil || len(exp.Alternative.Statements) != 1 {
+		t.Fatalf("else if alternative is not a single statement. got=%+v", exp.Alternative)
+	}
+
+	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T", exp.Alternative.Statements[0])
+	}
+
+	nested, ok := alternative.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("alternative is not a nested ast.IfExpression. got=%T", alternative.Expression)
+	}
+
+	if !testInfixIdentifiers(t, nested.Condition, "x", ">", "y") {
+		return
+	}
+
+	if nested.Alternative == nil {
+		t.Fatalf("nested.Alternative is nil")
+	}
+
+	expected := "if(x<y) xelse if(x>y) yelse z"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x, y) { x + y; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not an expression statement. got=%T", program.Statements[0])
+	}
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(function.Parameters) != 2 {
+		t.Fatalf("function literal parameters wrong. want 2, got=%d", len(function.Parameters))
+	}
+
+	testIdentifier(t, function.Parameters[0], "x")
+	testIdentifier(t, function.Parameters[1], "y")
+
+	if len(function.Body.Statements) != 1 {
+		t.Fatalf("function.Body.Statements has not 1 statement. got=%d", len(function.Body.Statements))
+	}
+
+	bodyStmt, ok := function.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("function body stmt is not ast.ExpressionStatement. got=%T", function.Body.Statements[0])
+	}
+
+	testInfixIdentifiers(t, bodyStmt.Expression, "x", "+", "y")
+}
+
+func TestFunctionParameterParsing(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+	}{
+		{"fn() {};", []string{}},
+		{"fn(x) {};", []string{"x"}},
+		{"fn(x, y, z) {};", []string{"x", "y", "z"}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function := stmt.Expression.(*ast.FunctionLiteral)
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d", len(tt.expectedParams), len(function.Parameters))
+		}
+
+		for i, ident := range tt.expectedParams {
+			testIdentifier(t, function.Parameters[i], ident)
+		}
+	}
+}
+
+func TestFunctionParameterErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn(1) {};", "1:4: expected next token to be IDENT, got INT instead"},
+		{"fn(x, 2) {};", "1:7: expected next token to be IDENT, got INT instead"},
+		{"fn(x, true) {};", "1:7: expected next token to be IDENT, got TRUE instead"},
+		{"fn(x y) {};", "1:6: expected next token to be ), got IDENT instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestCallExpressionParsing(t *testing.T) {
+	input := "add(1, 2 * 3, 4 + 5);"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not an expression statement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CallExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, exp.Function, "add") {
+		return
+	}
+
+	if len(exp.Arguments) != 3 {
+		t.Fatalf("wrong length of arguments. got=%d", len(exp.Arguments))
+	}
+
+	testIntegerLiteral(t, exp.Arguments[0], 1)
+	if exp.Arguments[1].String() != "(2*3)" {
+		t.Errorf("second argument wrong. got=%q", exp.Arguments[1].String())
+	}
+	if exp.Arguments[2].String() != "(4+5)" {
+		t.Errorf("third argument wrong. got=%q", exp.Arguments[2].String())
+	}
+}
+
+func TestStringLiteralExpression(t *testing.T) {
+	input := `"hello world";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := stmt.Expression.(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.StringLiteral. got=%T", stmt.Expression)
+	}
+
+	if literal.Value != "hello world" {
+		t.Errorf("literal.Value not %q. got=%q", "hello world", literal.Value)
+	}
+}
+
+func TestParsingArrayLiterals(t *testing.T) {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 3 {
+		t.Fatalf("len(array.Elements) not 3. got=%d", len(array.Elements))
+	}
+
+	testIntegerLiteral(t, array.Elements[0], 1)
+	if array.Elements[1].String() != "(2*2)" {
+		t.Errorf("second element wrong. got=%q", array.Elements[1].String())
+	}
+	if array.Elements[2].String() != "(3+3)" {
+		t.Errorf("third element wrong. got=%q", array.Elements[2].String())
+	}
+}
+
+func TestParsingEmptyArrayLiteral(t *testing.T) {
+	l := lexer.New("[]")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	if !ok {
+		t.Fatalf("exp not ast.ArrayLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(array.Elements) != 0 {
+		t.Errorf("len(array.Elements) not 0. got=%d", len(array.Elements))
+	}
+}
+
+func TestParsingIndexExpressions(t *testing.T) {
+	input := "myArray[1 + 1]"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.IndexExpression. got=%T", stmt.Expression)
+	}
+
+	if !testIdentifier(t, indexExp.Left, "myArray") {
+		return
+	}
+
+	if indexExp.Index.String() != "(1+1)" {
+		t.Errorf("index wrong. got=%q", indexExp.Index.String())
+	}
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		target   string
+		operator string
+		value    string
+	}{
+		{"x = 5;", "x", "=", "5"},
+		{"x += 1", "x", "+=", "1"},
+		{"x -= y * 2;", "x", "-=", "(y*2)"},
+		{"x *= -1", "x", "*=", "(-1)"},
+		{"x /= 2", "x", "/=", "2"},
+		{"x %= 3", "x", "%=", "3"},
+		{"arr[i] = v;", "(arr[i])", "=", "v"},
+		{`h["k"] -= 2`, "(h[k])", "-=", "2"},
+		{"m[0][1] = a == b", "((m[0])[1])", "=", "(a==b)"},
+		{"f(x)[0] = 1", "(f(x)[0])", "=", "1"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.AssignExpression. got=%T", stmt.Expression)
+		}
+
+		if exp.Target.String() != tt.target {
+			t.Errorf("exp.Target wrong for %q. expected=%q, got=%q", tt.input, tt.target, exp.Target.String())
+		}
+		if exp.Operator != tt.operator {
+			t.Errorf("exp.Operator wrong for %q. expected=%q, got=%q", tt.input, tt.operator, exp.Operator)
+		}
+		if exp.Value.String() != tt.value {
+			t.Errorf("exp.Value wrong for %q. expected=%q, got=%q", tt.input, tt.value, exp.Value.String())
+		}
+	}
+}
+
+func TestAssignExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 = 2", "1:3: cannot assign to 1"},
+		{"f() = 2", "1:5: cannot assign to f()"},
+		{"a + b += 1", "1:7: cannot assign to (a+b)"},
+		{"x = ;", "1:5: no prefix parse function for ; found"},
+		{"x = y = 1", "1:7: no prefix parse function for = found"},
+		{"x += 1 += 2", "1:8: no prefix parse function for += found"},
+		// the target is left incomplete by an earlier error
+		{"- ; += 1", "1:3: no prefix parse function for ; found"},
+		{"~ | = 1", "1:3: no prefix parse function for | found"},
+		{"x * / = 2", "1:5: no prefix parse function for / found"},
+		{"-[1 += 2", "1:5: expected next token to be ], got += instead"},
+		// assignment is a statement, not an operator of other expressions
+		{"if (x = 1) { x }", "1:7: expected next token to be ), got = instead"},
+		{"let y = x = 1;", "1:11: no prefix parse function for = found"},
+		{"f(x = 1)", "1:5: expected next token to be ), got = instead"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestParsingHashLiteralsStringKeys(t *testing.T) {
+	input := `{"one": 1, "two": 2, "three": 3}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	expected := []struct {
+		key   string
+		value int64
+	}{
+		{"one", 1},
+		{"two", 2},
+		{"three", 3},
+	}
+
+	if len(hash.Pairs) != len(expected) {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	for i, tt := range expected {
+		literal, ok := hash.Pairs[i].Key.(*ast.StringLiteral)
+		if !ok {
+			t.Errorf("key is not ast.StringLiteral. got=%T", hash.Pairs[i].Key)
+			continue
+		}
+
+		if literal.Value != tt.key {
+			t.Errorf("key is not %q. got=%q", tt.key, literal.Value)
+		}
+
+		testIntegerLiteral(t, hash.Pairs[i].Value, tt.value)
+	}
+}
+
+func TestParsingEmptyHashLiteral(t *testing.T) {
+	l := lexer.New("{}")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp is not ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 0 {
+		t.Errorf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+}
+
+func TestParsingHashLiteralsWithExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"one": 0 + 1, "two": 10 - 8}`, "{one:(0+1), two:(10-8)}"},
+		{`{1: true, false: 2, a: b}`, "{1:true, false:2, a:b}"},
+		{`let h = {"name": "x", 1: true}; h["name"]`, "let h = {name:x, 1:true};(h[name])"},
+		{`fn() { {"a": 1} }`, "fn() {a:1}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestHashLiteralErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"a" 1}`, "1:6: expected next token to be :, got INT instead"},
+		{`{"a": 1 "b": 2}`, "1:9: expected next token to be ,, got STRING instead"},
+		{`{"a": 1,`, "1:9: no prefix parse function for EOF found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected parser errors for %q, got none", tt.input)
+		}
+		if errors[0].Error() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := "let x = 1;\nx + add(2, y[0]);"
+
+	l := lexer.NewFile("pos.mk", input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[1].(*ast.ExpressionStatement)
+	infix := stmt.Expression.(*ast.InfixExpression)
+	call := infix.Right.(*ast.CallExpression)
+	index := call.Arguments[1].(*ast.IndexExpression)
+
+	tests := []struct {
+		node     ast.Node
+		expected string
+	}{
+		{program, "pos.mk:1:1"},
+		{program.Statements[0], "pos.mk:1:1"},
+		{program.Statements[0].(*ast.LetStatement).Value, "pos.mk:1:9"},
+		{infix, "pos.mk:2:1"},
+		{call, "pos.mk:2:5"},
+		{call.Arguments[0], "pos.mk:2:9"},
+		{index, "pos.mk:2:12"},
+		{index.Index, "pos.mk:2:14"},
+	}
+
+	for i, tt := range tests {
+		if tt.node.Pos().String() != tt.expected {
+			t.Errorf("tests[%d] - position wrong, expected=%q, got=%q", i, tt.expected, tt.node.Pos())
+		}
+	}
+}
+
+func TestNodeEnds(t *testing.T) {
+	input := "let s = \"a\\tb\";\nif (s) { [1, {\"k\": -x}] } else { fn(a) { a } }\nreturn x;"
+
+	l := lexer.NewFile("end.mk", input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	let := program.Statements[0].(*ast.LetStatement)
+	ifExp := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	array := ifExp.Consequence.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral)
+	hash := array.Elements[1].(*ast.HashLiteral)
+	function := ifExp.Alternative.Statements[0].(*ast.ExpressionStatement).Expression
+
+	tests := []struct {
+		node     ast.Node
+		expected string
+	}{
+		// the end of a string is after its closing quote, not its value
+		{let.Value, "end.mk:1:15"},
+		{let, "end.mk:1:15"},
+		{ifExp.Consequence, "end.mk:2:26"},
+		{array, "end.mk:2:24"},
+		{array.Elements[0], "end.mk:2:12"},
+		{hash, "end.mk:2:23"},
+		{hash.Pairs[0].Value, "end.mk:2:22"},
+		{function, "end.mk:2:45"},
+		{ifExp, "end.mk:2:47"},
+		{program.Statements[2], "end.mk:3:9"},
+		{program, "end.mk:3:9"},
+	}
+
+	for i, tt := range tests {
+		if tt.node.End().String() != tt.expected {
+			t.Errorf("tests[%d] - end wrong, expected=%q, got=%q", i, tt.expected, tt.node.End())
+		}
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	input := "let x = 1;\nlet y = (1 + 2;\n"
+
+	l := lexer.NewFile("err.mk", input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected parser errors, got none")
+	}
+
+	expected := "err.mk:2:15: expected next token to be ), got ; instead"
+	if errors[0].Error() != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, errors[0])
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "// add two numbers\nlet add = fn(a, b) { a + b }; # done\nadd(1, /* two */ 2)"
+
+	l := lexer.NewFileMode("", input, lexer.KeepComments)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	expected := []string{"// add two numbers", "# done", "/* two */"}
+	comments := p.Comments()
+	if len(comments) != len(expected) {
+		t.Fatalf("wrong number of comments. expected=%d, got=%d", len(expected), len(comments))
+	}
+	for i, literal := range expected {
+		if comments[i].Type != token.COMMENT || comments[i].Literal != literal {
+			t.Errorf("comments[%d] wrong. expected=%q, got=%q %q", i, literal, comments[i].Type, comments[i].Literal)
+		}
+	}
+}
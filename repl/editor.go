@@ -0,0 +1,488 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxHistory : the number of lines of history that are kept
+const maxHistory = 1000
+
+// errInterrupted : returned by readLine when ctrl-c is pressed
+var errInterrupted = errors.New("interrupted")
+
+// key codes of the control characters the editor understands
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// the keys sent as escape sequences are given codes outside of the
+// range of runes, so they can be handled in the same switch
+const (
+	keyUp rune = -(iota + 1)
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+	keyUnknown
+)
+
+// lineEditor : reads lines from a terminal in raw mode, supporting
+// cursor movement, history (up/down and ctrl-r search), and tab
+// completion of the word before the cursor
+type lineEditor struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// raw puts the terminal in raw mode, returning a function which
+	// restores it. Nil when in is not a terminal, e.g. in tests
+	raw func() (func(), error)
+
+	// complete returns the words which may be used to complete prefix
+	complete func(prefix string) []string
+
+	history     []string
+	historyFile string // where history is saved, if not empty
+
+	buf    []rune // the line being edited
+	pos    int    // index of the cursor in buf
+	prompt string
+}
+
+func newLineEditor(in io.Reader, out io.Writer, complete func(string) []string) *lineEditor {
+	return &lineEditor{
+		in:       bufio.NewReader(in),
+		out:      out,
+		complete: complete,
+	}
+}
+
+// historyPath : the file history is saved to, $GO_INTERPRETER_HISTORY
+// if set, or .go_interpreter_history in the home directory
+func historyPath() string {
+	if path := os.Getenv("GO_INTERPRETER_HISTORY"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".go_interpreter_history")
+}
+
+// loadHistory : read the history saved in path, and save
+// every line entered from now on to it
+func (e *lineEditor) loadHistory(path string) {
+	e.historyFile = path
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
+
+// addHistory : add line to the history, unless it is
+// blank or the same as the line before it
+func (e *lineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+
+	if e.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(e.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// readLine : show prompt, and read a line of input, returning io.EOF if
+// ctrl-d is pressed on an empty line, or errInterrupted for ctrl-c
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if e.raw != nil {
+		restore, err := e.raw()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	e.prompt = prompt
+	e.buf = e.buf[:0]
+	e.pos = 0
+	e.refresh()
+
+	// index into history of the line being shown, the line
+	// being entered is at len(history), and saved when moving up
+	histIdx := len(e.history)
+	entered := ""
+
+	for {
+		r, err := e.readKey()
+		if err != nil {
+			if err == io.EOF && len(e.buf) > 0 {
+				break
+			}
+			return "", err
+		}
+
+		switch r {
+		case keyCR, keyLF:
+			fmt.Fprint(e.out, "\r\n")
+			line := string(e.buf)
+			e.addHistory(line)
+			return line, nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteRune()
+		case keyBackspace, keyCtrlH:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteRune()
+			}
+		case keyDelete:
+			e.deleteRune()
+		case keyLeft, keyCtrlB:
+			if e.pos > 0 {
+				e.pos--
+			}
+		case keyRight, keyCtrlF:
+			if e.pos < len(e.buf) {
+				e.pos++
+			}
+		case keyHome, keyCtrlA:
+			e.pos = 0
+		case keyEnd, keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append(e.buf[:0], e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			// delete the spaces before the cursor, and the word before them
+			start := e.pos
+			for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+				start--
+			}
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+		case keyCtrlL:
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case keyUp, keyCtrlP:
+			if histIdx > 0 {
+				if histIdx == len(e.history) {
+					entered = string(e.buf)
+				}
+				histIdx--
+				e.setLine(e.history[histIdx])
+			}
+		case keyDown, keyCtrlN:
+			if histIdx < len(e.history) {
+				histIdx++
+				if histIdx == len(e.history) {
+					e.setLine(entered)
+				} else {
+					e.setLine(e.history[histIdx])
+				}
+			}
+		case keyCtrlR:
+			line, submit, err := e.search()
+			if err != nil {
+				return "", err
+			}
+			e.setLine(line)
+			if submit {
+				fmt.Fprint(e.out, "\r\n")
+				e.addHistory(line)
+				return line, nil
+			}
+		case keyTab:
+			e.completeWord()
+		default:
+			if r >= ' ' {
+				e.insert(r)
+			}
+		}
+
+		e.refresh()
+	}
+
+	// the input ended part way through a line
+	fmt.Fprint(e.out, "\r\n")
+	line := string(e.buf)
+	e.addHistory(line)
+	return line, nil
+}
+
+// readKey : read a single key press, translating escape
+// sequences into the key codes above
+func (e *lineEditor) readKey() (rune, error) {
+	r, _, err := e.in.ReadRune()
+	if err != nil || r != keyEscape {
+		return r, err
+	}
+
+	// ESC [ X or ESC O X, where X is the key, or
+	// ESC [ n ~ for keys such as delete
+	next, _, err := e.in.ReadRune()
+	if err != nil {
+		return keyUnknown, err
+	}
+	if next != '[' && next != 'O' {
+		return keyUnknown, nil
+	}
+
+	code, _, err := e.in.ReadRune()
+	if err != nil {
+		return keyUnknown, err
+	}
+
+	switch code {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	case 'H':
+		return keyHome, nil
+	case 'F':
+		return keyEnd, nil
+	}
+
+	if code >= '0' && code <= '9' {
+		// read up to the terminating ~
+		seq := []rune{code}
+		for {
+			c, _, err := e.in.ReadRune()
+			if err != nil {
+				return keyUnknown, err
+			}
+			if c == '~' {
+				break
+			}
+			seq = append(seq, c)
+		}
+
+		switch string(seq) {
+		case "1", "7":
+			return keyHome, nil
+		case "3":
+			return keyDelete, nil
+		case "4", "8":
+			return keyEnd, nil
+		}
+	}
+
+	return keyUnknown, nil
+}
+
+// search : incremental reverse search of the history, started with
+// ctrl-r. Returns the line found, and whether enter was pressed to
+// run it straight away. Pressing ctrl-r again finds an older match
+func (e *lineEditor) search() (string, bool, error) {
+	original := string(e.buf)
+	query := []rune{}
+	match := ""
+	idx := len(e.history)
+
+	// find the newest line before from containing the query
+	find := func(from int) bool {
+		if from > len(e.history) {
+			from = len(e.history)
+		}
+		for i := from - 1; i >= 0; i-- {
+			if strings.Contains(e.history[i], string(query)) {
+				idx = i
+				match = e.history[i]
+				return true
+			}
+		}
+		return false
+	}
+
+	for {
+		fmt.Fprintf(e.out, "\r(reverse-i-search)`%s': %s\x1b[K", string(query), match)
+
+		r, err := e.readKey()
+		if err != nil {
+			return "", false, err
+		}
+
+		switch r {
+		case keyCtrlR:
+			find(idx)
+		case keyBackspace, keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				idx = len(e.history)
+				match = ""
+				find(idx)
+			}
+		case keyCtrlC, keyCtrlG:
+			return original, false, nil
+		case keyCR, keyLF:
+			return match, true, nil
+		default:
+			if r >= ' ' {
+				query = append(query, r)
+				// the current match may still contain the longer query
+				if !find(idx + 1) {
+					match = ""
+				}
+				continue
+			}
+			// any other key ends the search, keeping the match
+			if match == "" {
+				return original, false, nil
+			}
+			return match, false, nil
+		}
+	}
+}
+
+// completeWord : complete the word before the cursor, if there is only
+// one candidate it is inserted, otherwise the common prefix of all of
+// the candidates is inserted, and they are listed if there is none
+func (e *lineEditor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+
+	start := e.pos
+	for start > 0 && isWordRune(e.buf[start-1]) {
+		start--
+	}
+	prefix := string(e.buf[start:e.pos])
+	if prefix == "" {
+		return
+	}
+
+	candidates := e.complete(prefix)
+	if len(candidates) == 0 {
+		return
+	}
+
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		common = commonPrefix(common, c)
+	}
+
+	if common != prefix {
+		for _, r := range common[len(prefix):] {
+			e.insert(r)
+		}
+		return
+	}
+
+	if len(candidates) > 1 {
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// commonPrefix : the longest prefix of whole runes a and b share, which
+// stops before a rune they both start with the same bytes of, e.g. the
+// é and è of café and cafè
+func commonPrefix(a, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	for i > 0 && i < len(a) && !utf8.RuneStart(a[i]) {
+		i--
+	}
+	return a[:i]
+}
+
+func (e *lineEditor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+// deleteRune : delete the rune under the cursor
+func (e *lineEditor) deleteRune() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+func (e *lineEditor) setLine(line string) {
+	e.buf = append(e.buf[:0], []rune(line)...)
+	e.pos = len(e.buf)
+}
+
+// refresh : redraw the prompt and line, and move the cursor into place
+func (e *lineEditor) refresh() {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, string(e.buf))
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
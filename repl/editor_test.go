@@ -0,0 +1,177 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-interpreter/object"
+)
+
+const (
+	up    = "\x1b[A"
+	down  = "\x1b[B"
+	right = "\x1b[C"
+	left  = "\x1b[D"
+	del   = "\x1b[3~"
+)
+
+func TestLineEditing(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"abc\r", "abc"},
+		{"ac" + left + "b\r", "abc"},
+		{"abd\x7fc\r", "abc"},
+		{"abc" + left + left + del + "\n", "ac"},
+		{"bc\x01a\x05d\r", "abcd"},
+		{"abc" + left + left + "\x0b\r", "a"},
+		{"abc" + left + "\x15\r", "c"},
+		{"let foo  \x17\r", "let "},
+		{"a" + left + left + right + right + "b\r", "ab"},
+		{"héllo" + left + "\x7f\r", "hélo"},
+		{"partial", "partial"},
+	}
+
+	for _, tt := range tests {
+		e := newLineEditor(strings.NewReader(tt.keys), io.Discard, nil)
+
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.keys, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%q: wrong line. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
+
+func TestLineEditorControl(t *testing.T) {
+	e := newLineEditor(strings.NewReader("\x04"), io.Discard, nil)
+	if _, err := e.readLine(PROMPT); err != io.EOF {
+		t.Errorf("ctrl-d on empty line should return io.EOF. got=%v", err)
+	}
+
+	e = newLineEditor(strings.NewReader("abc\x03"), io.Discard, nil)
+	if _, err := e.readLine(PROMPT); err != errInterrupted {
+		t.Errorf("ctrl-c should return errInterrupted. got=%v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{up + "\r", "x + 1"},
+		{up + up + "\r", "let x = 1"},
+		{up + up + up + up + "\r", "let x = 1"},
+		{"new" + up + down + "\r", "new"},
+		{up + up + down + "\r", "x + 1"},
+		{"\x12let\r", "let x = 1"},
+		{"\x12x\r", "x + 1"},
+		{"\x12x\x12\r", "let x = 1"},
+		{"\x12x\x12" + right + "!\r", "let x = 1!"},
+		{"typed\x12zzz\x07\r", "typed"},
+	}
+
+	for _, tt := range tests {
+		e := newLineEditor(strings.NewReader(tt.keys), io.Discard, nil)
+		e.history = []string{"let x = 1", "x + 1"}
+
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.keys, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%q: wrong line. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	e := newLineEditor(strings.NewReader("let a = 1;\r\r1 + 1\r1 + 1\r"), io.Discard, nil)
+	e.loadHistory(path)
+	for i := 0; i < 4; i++ {
+		if _, err := e.readLine(PROMPT); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "let a = 1;\n1 + 1\n" {
+		t.Errorf("wrong history file. got=%q", string(data))
+	}
+
+	e = newLineEditor(strings.NewReader(up+up+"\r"), io.Discard, nil)
+	e.loadHistory(path)
+	line, err := e.readLine(PROMPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line != "let a = 1;" {
+		t.Errorf("history not loaded. got=%q", line)
+	}
+}
+
+func TestTabCompletion(t *testing.T) {
+	env := object.NewEnvironment()
+	env.Set("foo", &object.Integer{Value: 1})
+	env.Set("foobar", &object.Integer{Value: 2})
+	env.Set("result", &object.Integer{Value: 3})
+	env.Set("café", &object.Integer{Value: 4})
+	env.Set("cafè", &object.Integer{Value: 5})
+	env.Set("π", &object.Integer{Value: 6})
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"ret\t\r", "return"},
+		{"le\t x\r", "let x"},
+		{"res\t\r", "result"},
+		{"1 + fo\t\r", "1 + foo"},
+		{"1 + foob\t\r", "1 + foobar"},
+		// the common prefix of café and cafè ends before the é and è
+		{"ca\t\r", "caf"},
+		{"café\t\r", "café"},
+		{"1 + π\t\r", "1 + π"},
+		{"cafè\x7f\t\r", "caf"},
+		{"zzz\t\r", "zzz"},
+		{"\t\r", ""},
+	}
+
+	for _, tt := range tests {
+		e := newLineEditor(strings.NewReader(tt.keys), io.Discard, completer(env))
+
+		line, err := e.readLine(PROMPT)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.keys, err)
+			continue
+		}
+		if line != tt.expected {
+			t.Errorf("%q: wrong line. expected=%q, got=%q", tt.keys, tt.expected, line)
+		}
+	}
+
+	// the candidates are listed when there is no common prefix to add
+	var out bytes.Buffer
+	e := newLineEditor(strings.NewReader("f\t\r"), &out, completer(env))
+	if _, err := e.readLine(PROMPT); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "false  fn  foo  foobar") {
+		t.Errorf("candidates not listed. got=%q", out.String())
+	}
+}
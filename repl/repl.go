@@ -4,37 +4,123 @@ import (
 	"bufio"
 	"fmt"
 	"go-interpreter/lexer"
+	"go-interpreter/object"
 	"go-interpreter/token"
 	"io"
+	"os"
+	"sort"
+	"strings"
 )
 
 const PROMPT = "go-interpreter>> "
 
+// CONTINUE_PROMPT : shown instead of PROMPT while the
+// input entered so far is not a complete statement
+const CONTINUE_PROMPT = "              .. "
+
+// mode : what the REPL does with each line that is entered
+type mode string
+
+const (
+	modeTokens mode = "tokens" // print each token of the line
+	modeAST    mode = "ast"    // print the parsed program
+	modeEval   mode = "eval"   // evaluate the line, and print the result
+)
+
+// Start : run a REPL session reading from in, and writing
+// both results and errors to out
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
+	NewSession(in, out, out).Run()
+}
 
-	for {
-		// endless loop
+// lineReader : shows prompt, and reads the next line of input
+type lineReader func(prompt string) (string, error)
+
+// newLineReader : if in is a terminal, lines are read with the line
+// editor, otherwise they are scanned from in with no editing
+func newLineReader(in io.Reader, out io.Writer, env *object.Environment) lineReader {
+	if f, ok := in.(*os.File); ok && isTerminal(f.Fd()) {
+		editor := newLineEditor(f, out, completer(env))
+		editor.raw = func() (func(), error) { return makeRaw(f.Fd()) }
+		editor.loadHistory(historyPath())
+		return editor.readLine
+	}
+
+	scanner := bufio.NewScanner(in)
+	return func(prompt string) (string, error) {
+		fmt.Fprint(out, prompt)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}
+		return scanner.Text(), nil
+	}
+}
 
-		// print prompt
-		fmt.Printf(PROMPT)
+// completer : completes a prefix with the keywords, and
+// the names bound in env at the time of completion
+func completer(env *object.Environment) func(string) []string {
+	return func(prefix string) []string {
+		var candidates []string
+		seen := make(map[string]bool)
 
-		// scan input, if no input, exit REPL
-		scanned := scanner.Scan()
-		if !scanned {
-			return
+		for _, words := range [][]string{token.Keywords(), env.Names()} {
+			for _, word := range words {
+				if strings.HasPrefix(word, prefix) && !seen[word] {
+					seen[word] = true
+					candidates = append(candidates, word)
+				}
+			}
 		}
 
-		// get scanned text
-		line := scanner.Text()
-		// create new lexer, calling New function from
-		// lexer package, which creates lexer with
-		// scanned line as input
-		lexer := lexer.New(line)
+		sort.Strings(candidates)
+		return candidates
+	}
+}
+
+// incomplete : whether more input is needed to complete the statement in
+// input, because a bracket, string or comment has been opened and not closed,
+// or because the input ends with an operator that needs a right side
+func incomplete(input string) bool {
+	l := lexer.New(input)
+	depth := 0
+	var last token.Token
 
-		// iterate through the tokens, while we have not reached an EOF token
-		for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
-			fmt.Printf("%+v\n", tok)
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPAREN, token.RBRACE, token.RBRACKET:
+			depth--
+		case token.ILLEGAL:
+			// a string or block comment which runs to the end of the input
+			if (strings.HasPrefix(tok.Literal, `"`) || strings.HasPrefix(tok.Literal, "/*")) &&
+				tok.Pos.Offset+len(tok.Literal) == len(input) {
+				return true
+			}
 		}
+		last = tok
 	}
+
+	if depth > 0 {
+		return true
+	}
+	// too many closing brackets will never be completed,
+	// so let the parser report the error
+	if depth < 0 {
+		return false
+	}
+
+	switch last.Type {
+	case token.ASSIGN, token.PLUS, token.MINUS, token.TIMES, token.SLASH, token.BANG,
+		token.LT, token.GT, token.EQ, token.NEQ, token.COMMA, token.COLON,
+		token.LTE, token.GTE, token.MODULO, token.POWER, token.AND, token.OR,
+		token.BITAND, token.BITOR, token.BITXOR, token.BITNOT, token.SHL, token.SHR,
+		token.PLUS_ASSIGN, token.MINUS_ASSIGN, token.TIMES_ASSIGN, token.SLASH_ASSIGN, token.MODULO_ASSIGN:
+		return true
+	}
+
+	return false
 }
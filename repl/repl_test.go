@@ -0,0 +1,187 @@
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func stripPrompts(output string) string {
+	output = strings.ReplaceAll(output, PROMPT, "")
+	return strings.ReplaceAll(output, CONTINUE_PROMPT, "")
+}
+
+func TestPromptWrittenToOut(t *testing.T) {
+	var out bytes.Buffer
+	Start(strings.NewReader("let f = fn(x) {\nx }\n"), &out)
+
+	expected := PROMPT + CONTINUE_PROMPT + PROMPT
+	if out.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestModes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2\n", "3\n"},
+		{"let x = 5;\nx * 2\n", "10\n"},
+		{"let f = fn(a) { a + 1 };\nf(x)\n", "<repl:2>:1:3: error: identifier not found: x\n"},
+		{":mode tokens\nlet x\n", "mode: tokens\n" +
+			"{Type:LET Literal:let Pos:1:1 End:1:4}\n" +
+			"{Type:IDENT Literal:x Pos:1:5 End:1:6}\n"},
+		{":mode ast\n1 + 2 * 3\n", "mode: ast\n(1+(2*3))\n"},
+		{":mode ast\nlet = 1;\n", "mode: ast\n1:5: error: expected next token to be IDENT, got = instead\n"},
+		{":mode ast\n:mode eval\nlet y = 2; y\n", "mode: ast\nmode: eval\n2\n"},
+		{":mode\n", "mode: eval\n"},
+		{":mode bogus\n", "unknown mode \"bogus\""},
+		{":quit\n", "unknown command \":quit\""},
+		{"foo\n", "<repl:1>:1:1: error: identifier not found: foo\n"},
+		// an error in a function is shown against the input that defined it
+		{"let f = fn(x) {\n  x + true\n};\n1\nf(1)\n", "1\n" +
+			"<repl:1>:2:3: error: type mismatch: INTEGER + BOOLEAN\n" +
+			"   2 |   x + true\n" +
+			"     |   ^~~~~~~~\n"},
+		{"let add = fn(a, b) {\n  a + b\n};\nadd(1, 2)\n", "3\n"},
+		{"1 +\n2 *\n3\n", "7\n"},
+		{"[1,\n2][1]\n", "2\n"},
+		{"\"abc\ndef\"\n", "abc\ndef\n"},
+		{"let x = (1 + 2\n", "<repl:1>:1:15: error: expected next token to be ), got EOF instead\n"},
+		{":mode ast\nif (x) {\n1 } else {\n2 }\n", "mode: ast\nifx 1else 2\n"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(tt.input), &out)
+
+		actual := stripPrompts(out.String())
+		if !strings.HasPrefix(actual, tt.expected) {
+			t.Errorf("wrong output for %q.\nexpected prefix:\n%s\ngot:\n%s", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5;", false},
+		{"let f = fn(x) {", true},
+		{"let f = fn(x) { x }", false},
+		{"add(1,", true},
+		{"add(1, 2", true},
+		{"[1, 2", true},
+		{"let x = 1 +", true},
+		{"let x =", true},
+		{"a ==", true},
+		{`{"a":`, true},
+		{`"unterminated`, true},
+		{`"done"`, false},
+		{"let x = 1 <=", true},
+		{"x =", true},
+		{"x +=", true},
+		{"arr[0] %=", true},
+		{"x += 1", false},
+		{"let x = 2 **", true},
+		{"let ok = a &&", true},
+		{"let ok = a ||", true},
+		{"let m = 1 <<", true},
+		{"let m = ~", true},
+		{"let x = 1; /* note", true},
+		{"let x = 1; /* a /* b */", true},
+		{"let x = 1; /* a /* b */ */", false},
+		{"let x = 1 + // one more", true},
+		{"}", false},
+		{"(1))", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if actual := incomplete(tt.input); actual != tt.expected {
+			t.Errorf("incomplete(%q) wrong. expected=%t, got=%t", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestSessionEval(t *testing.T) {
+	tests := []struct {
+		lines       []string
+		expectedOut string
+		expectedErr string
+	}{
+		{[]string{"let x = 5;", "x * 2"}, "10\n", ""},
+		{[]string{"let add = fn(a, b) {", "a + b", "};", "add(1, 2)"}, "3\n", ""},
+		{[]string{":mode ast", "1 + 2 * 3"}, "mode: ast\n(1+(2*3))\n", ""},
+		{[]string{":mode nope"}, "", "unknown mode \"nope\", expected one of tokens, ast, eval\n"},
+		{[]string{":quit"}, "", "unknown command \":quit\", expected :mode tokens|ast|eval\n"},
+	}
+
+	for _, tt := range tests {
+		var out, errOut bytes.Buffer
+		s := NewSession(strings.NewReader(""), &out, &errOut)
+		for _, line := range tt.lines {
+			s.Eval(line)
+		}
+
+		if out.String() != tt.expectedOut {
+			t.Errorf("wrong output for %q. expected=%q, got=%q", tt.lines, tt.expectedOut, out.String())
+		}
+		if errOut.String() != tt.expectedErr {
+			t.Errorf("wrong errors for %q. expected=%q, got=%q", tt.lines, tt.expectedErr, errOut.String())
+		}
+	}
+}
+
+func TestSessionErrorsWrittenToErr(t *testing.T) {
+	var out, errOut bytes.Buffer
+	s := NewSession(strings.NewReader(""), &out, &errOut)
+	s.Eval("foo + 1")
+	s.Eval("let = 5;")
+
+	if out.Len() != 0 {
+		t.Errorf("expected no output, got=%q", out.String())
+	}
+	for _, msg := range []string{"identifier not found: foo", "expected next token to be IDENT"} {
+		if !strings.Contains(errOut.String(), msg) {
+			t.Errorf("errors do not contain %q. got=%q", msg, errOut.String())
+		}
+	}
+}
+
+func TestSessionPrompt(t *testing.T) {
+	s := NewSession(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	s.Prompt = "> "
+	s.ContinuePrompt = ". "
+
+	if s.CurrentPrompt() != "> " {
+		t.Errorf("wrong prompt. got=%q", s.CurrentPrompt())
+	}
+	if s.Eval("let x = [1,") {
+		t.Errorf("expected statement to be incomplete")
+	}
+	if s.CurrentPrompt() != ". " {
+		t.Errorf("wrong continue prompt. got=%q", s.CurrentPrompt())
+	}
+	if !s.Eval("2]") {
+		t.Errorf("expected statement to be complete")
+	}
+	if s.CurrentPrompt() != "> " {
+		t.Errorf("wrong prompt after statement. got=%q", s.CurrentPrompt())
+	}
+}
+
+func TestSessionRunUsesPrompt(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSession(strings.NewReader("1 +\n2\n"), &out, &out)
+	s.Prompt = "> "
+	s.ContinuePrompt = ". "
+	s.Run()
+
+	expected := "> . 3\n> "
+	if out.String() != expected {
+		t.Errorf("wrong output. expected=%q, got=%q", expected, out.String())
+	}
+}
@@ -0,0 +1,213 @@
+package repl
+
+import (
+	"fmt"
+	"go-interpreter/diag"
+	"go-interpreter/evaluator"
+	"go-interpreter/lexer"
+	"go-interpreter/object"
+	"go-interpreter/parser"
+	"go-interpreter/token"
+	"io"
+	"strings"
+)
+
+// Session : a REPL session, which keeps the environment, mode and any
+// incomplete statement between lines. Lines can either be read from In
+// by Run, or fed to the session one at a time with Eval. Results are
+// written to Out, and errors to Err
+type Session struct {
+	Prompt         string // shown before each statement
+	ContinuePrompt string // shown while a statement is not yet complete
+
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+
+	env     *object.Environment
+	mode    mode
+	pending []string // lines of a statement which is not yet complete
+
+	// inputs are the statements evaluated so far, the Nth is named
+	// <repl:N> in positions, so that an error in a function defined by
+	// an earlier input is shown against the source it was defined in
+	inputs []string
+}
+
+// NewSession : create new session in eval mode, with the default prompts
+func NewSession(in io.Reader, out, err io.Writer) *Session {
+	return &Session{
+		Prompt:         PROMPT,
+		ContinuePrompt: CONTINUE_PROMPT,
+		In:             in,
+		Out:            out,
+		Err:            err,
+		env:            object.NewEnvironment(),
+		mode:           modeEval,
+	}
+}
+
+// Env : the environment lines are evaluated in
+func (s *Session) Env() *object.Environment {
+	return s.env
+}
+
+// CurrentPrompt : the prompt to show before the next line
+func (s *Session) CurrentPrompt() string {
+	if len(s.pending) != 0 {
+		return s.ContinuePrompt
+	}
+	return s.Prompt
+}
+
+// Run : read lines from In until there is no more input, running
+// each one, with line editing if In is a terminal
+func (s *Session) Run() {
+	readLine := newLineReader(s.In, s.Out, s.env)
+
+	for {
+		line, err := readLine(s.CurrentPrompt())
+
+		// ctrl-c discards the statement being entered
+		if err == errInterrupted {
+			s.Reset()
+			continue
+		}
+
+		// if no input, run whatever is pending, and exit
+		if err != nil {
+			s.Flush()
+			return
+		}
+
+		s.Eval(line)
+	}
+}
+
+// Eval : handle line as if it had been entered at the prompt. Returns
+// false if the statement is not complete yet, in which case the line
+// is kept, and run with the lines that follow it
+func (s *Session) Eval(line string) bool {
+	// lines starting with : are commands to the REPL itself
+	if len(s.pending) == 0 && strings.HasPrefix(strings.TrimSpace(line), ":") {
+		s.metaCommand(line)
+		return true
+	}
+
+	// keep reading lines until the statement is complete
+	s.pending = append(s.pending, line)
+	input := strings.Join(s.pending, "\n")
+	if incomplete(input) {
+		return false
+	}
+	s.pending = nil
+
+	s.run(input)
+	return true
+}
+
+// Flush : run the pending lines of an incomplete statement, if any
+func (s *Session) Flush() {
+	if len(s.pending) != 0 {
+		input := strings.Join(s.pending, "\n")
+		s.pending = nil
+		s.run(input)
+	}
+}
+
+// Reset : discard the pending lines of an incomplete statement
+func (s *Session) Reset() {
+	s.pending = nil
+}
+
+// run : handle input according to the current mode
+func (s *Session) run(input string) {
+	switch s.mode {
+	case modeTokens:
+		s.printTokens(input)
+	case modeAST:
+		s.printAST(input)
+	case modeEval:
+		s.evalInput(input)
+	}
+}
+
+// metaCommand : run a : command, e.g. to change the mode
+func (s *Session) metaCommand(line string) {
+	fields := strings.Fields(strings.TrimSpace(line))
+
+	switch fields[0] {
+	case ":mode":
+		if len(fields) == 1 {
+			fmt.Fprintf(s.Out, "mode: %s\n", s.mode)
+			return
+		}
+		switch m := mode(fields[1]); m {
+		case modeTokens, modeAST, modeEval:
+			s.mode = m
+			fmt.Fprintf(s.Out, "mode: %s\n", m)
+			return
+		}
+		fmt.Fprintf(s.Err, "unknown mode %q, expected one of tokens, ast, eval\n", fields[1])
+	default:
+		fmt.Fprintf(s.Err, "unknown command %q, expected :mode tokens|ast|eval\n", fields[0])
+	}
+}
+
+func (s *Session) printTokens(input string) {
+	// create new lexer, calling New function from
+	// lexer package, which creates lexer with
+	// scanned line as input
+	l := lexer.New(input)
+
+	// iterate through the tokens, while we have not reached an EOF token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(s.Out, "%+v\n", tok)
+	}
+}
+
+func (s *Session) printAST(input string) {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 0 {
+		diag.PrintParseErrors(s.Err, input, errors)
+		return
+	}
+
+	fmt.Fprintln(s.Out, program.String())
+}
+
+func (s *Session) evalInput(input string) {
+	s.inputs = append(s.inputs, input)
+	p := parser.New(lexer.NewFile(inputName(len(s.inputs)), input))
+	program := p.ParseProgram()
+	if errors := p.Errors(); len(errors) != 0 {
+		diag.PrintParseErrors(s.Err, input, errors)
+		return
+	}
+
+	evaluated := evaluator.Eval(program, s.env)
+	if err, ok := evaluated.(*object.Error); ok {
+		diag.PrintRuntimeError(s.Err, s.source(err.Pos.Filename), err)
+		return
+	}
+
+	if evaluated != nil {
+		fmt.Fprintln(s.Out, evaluated.Inspect())
+	}
+}
+
+// inputName : the filename used in the positions of the nth input
+func inputName(n int) string {
+	return fmt.Sprintf("<repl:%d>", n)
+}
+
+// source : the input with the given name, or "" if there is none
+func (s *Session) source(name string) string {
+	for i, input := range s.inputs {
+		if inputName(i+1) == name {
+			return input
+		}
+	}
+	return ""
+}
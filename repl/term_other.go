@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package repl
+
+import "errors"
+
+// isTerminal : the line editor is only supported on unix-like
+// systems, elsewhere input is always read a line at a time
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw mode is not supported on this platform")
+}
@@ -0,0 +1,46 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package repl
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal : whether fd refers to a terminal
+func isTerminal(fd uintptr) bool {
+	var termios syscall.Termios
+	return ioctl(fd, ioctlGetTermios, &termios) == nil
+}
+
+// makeRaw : put the terminal fd in raw mode, so that key presses are
+// read one at a time without being echoed, returning a function that
+// restores the terminal to its previous state. Output processing is
+// left on, so that \n still moves to the start of the next line
+func makeRaw(fd uintptr) (func(), error) {
+	var old syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, &old); err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.BRKINT | syscall.ICRNL | syscall.INPCK | syscall.ISTRIP | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.IEXTEN | syscall.ISIG
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err := ioctl(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() { ioctl(fd, ioctlSetTermios, &old) }, nil
+}
+
+func ioctl(fd, request uintptr, termios *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
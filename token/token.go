@@ -1,20 +1,63 @@
 package token
 
+import (
+	"fmt"
+	"sort"
+)
+
 type TokenType string
 
-// every token consists of type, and literal value
+// every token consists of type, literal value, the position
+// in the source at which the token starts, and the position
+// just after its last character
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Pos
+	End     Pos
+}
+
+// Pos : a position in the source, lines and columns start at 1, columns
+// count characters (runes) rather than bytes, and the offset is the
+// number of bytes from the start of the input
+type Pos struct {
+	Filename string
+	Line     int
+	Column   int
+	Offset   int
+}
+
+// IsValid : whether the position was set by the lexer
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+// String : formats the position as file:line:column,
+// or line:column if there is no filename
+func (p Pos) String() string {
+	if !p.IsValid() {
+		if p.Filename != "" {
+			return p.Filename
+		}
+		return "-"
+	}
+
+	if p.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT" // only produced when the lexer keeps comments
 
 	// identifiers + literals
-	IDENT = "IDENT" // add, x, y
-	INT   = "INT"   // 0..9
+	IDENT  = "IDENT"  // add, x, y
+	INT    = "INT"    // 0..9, 0x1F, 0o17, 0b101, 1_000
+	FLOAT  = "FLOAT"  // 1.5, 1e9, 2.5e-3
+	STRING = "STRING" // "foo bar"
 
 	// operators
 	EQ     = "=="
@@ -27,15 +70,39 @@ const (
 	TIMES  = "*"
 	LT     = "<"
 	GT     = ">"
+	LTE    = "<="
+	GTE    = ">="
+	MODULO = "%"
+	POWER  = "**"
+
+	AND = "&&"
+	OR  = "||"
+
+	BITAND = "&"
+	BITOR  = "|"
+	BITXOR = "^"
+	BITNOT = "~"
+	SHL    = "<<"
+	SHR    = ">>"
+
+	// compound assignment
+	PLUS_ASSIGN   = "+="
+	MINUS_ASSIGN  = "-="
+	TIMES_ASSIGN  = "*="
+	SLASH_ASSIGN  = "/="
+	MODULO_ASSIGN = "%="
 
 	// delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
 
-	LPAREN = "("
-	RPAREN = ")"
-	LBRACE = "{"
-	RBRACE = "}"
+	LPAREN   = "("
+	RPAREN   = ")"
+	LBRACE   = "{"
+	RBRACE   = "}"
+	LBRACKET = "["
+	RBRACKET = "]"
 
 	// keywords
 	LET      = "LET"
@@ -67,3 +134,13 @@ func LookupIdent(ident string) TokenType {
 
 	return IDENT
 }
+
+// Keywords : the identifiers which are reserved as keywords, sorted
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}